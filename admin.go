@@ -1,12 +1,15 @@
 package keycloak
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 const (
@@ -66,6 +69,24 @@ type Client struct {
 	WebOrigins                         *[]string               `json:"webOrigins,omitempty"`
 }
 
+// APIError is returned when the Keycloak admin API responds with a non-successful status code.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// ClientSecret represents a Keycloak client secret credential.
+type ClientSecret struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
 // AdminClient is a Keycloak admin client.
 type AdminClient struct {
 	ServerURL string
@@ -139,6 +160,104 @@ func (a *AdminClient) GetClient(realm string, clientID string) (*Client, error)
 	return nil, fmt.Errorf("client not found")
 }
 
+// UpdateClient updates the Keycloak client identified by its (internal) ID.
+func (a *AdminClient) UpdateClient(ctx context.Context, realm string, c Client) error {
+	if c.ID == nil {
+		return fmt.Errorf("client id must be provided")
+	}
+	return a.doRequest(ctx, http.MethodPut, "/"+url.PathEscape(realm)+"/clients/"+url.PathEscape(*c.ID), c, nil)
+}
+
+// GetClientSecret returns the secret of a confidential Keycloak client.
+func (a *AdminClient) GetClientSecret(ctx context.Context, realm, clientID string) (string, error) {
+	path, err := a.clientPath(realm, clientID)
+	if err != nil {
+		return "", err
+	}
+
+	var secret ClientSecret
+	if err = a.doRequest(ctx, http.MethodGet, path+"/client-secret", nil, &secret); err != nil {
+		return "", err
+	}
+
+	return secret.Value, nil
+}
+
+// doRequest performs an authorized call to the admin REST API of the realms path.
+// If body is not nil it is sent as JSON, if out is not nil the response is decoded into it.
+func (a *AdminClient) doRequest(ctx context.Context, method, path string, body, out interface{}) error {
+	_, err := a.send(ctx, method, path, body, out)
+	return err
+}
+
+// createResource POSTs the body to the admin REST API and returns the id of the created resource
+// taken from the Location header of the response.
+func (a *AdminClient) createResource(ctx context.Context, path string, body interface{}) (string, error) {
+	header, err := a.send(ctx, http.MethodPost, path, body, nil)
+	if err != nil {
+		return "", err
+	}
+
+	location := header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("POST %s: no Location header in the response", path)
+	}
+	return location[strings.LastIndex(location, "/")+1:], nil
+}
+
+func (a *AdminClient) send(ctx context.Context, method, path string, body, out interface{}) (http.Header, error) {
+	return a.sendURL(ctx, method, a.ServerURL+"/admin/realms"+path, body, out)
+}
+
+// sendURL sends an authorized request to an admin API endpoint outside of /admin/realms, e.g. /admin/serverinfo.
+func (a *AdminClient) sendURL(ctx context.Context, method, rawURL string, body, out interface{}) (http.Header, error) {
+	token, err := a.getToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Authorization", "Bearer "+token.AccessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{
+			Method:     method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Body:       string(msg),
+		}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return resp.Header, nil
+	}
+
+	return resp.Header, json.NewDecoder(resp.Body).Decode(out)
+}
+
 func (a *AdminClient) getToken() (*Token, error) {
 	var token Token
 
@@ -0,0 +1,278 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Requirements of an authentication execution.
+const (
+	RequirementRequired    = "REQUIRED"
+	RequirementAlternative = "ALTERNATIVE"
+	RequirementConditional = "CONDITIONAL"
+	RequirementDisabled    = "DISABLED"
+)
+
+// Types of an authentication sub-flow.
+const (
+	BasicFlow = "basic-flow"
+	FormFlow  = "form-flow"
+
+	formFlowProvider = "registration-page-form"
+)
+
+// RealmFlowBinding is a realm attribute an authentication flow can be bound to.
+type RealmFlowBinding string
+
+// Realm flow bindings.
+const (
+	BrowserFlowBinding              RealmFlowBinding = "browserFlow"
+	RegistrationFlowBinding         RealmFlowBinding = "registrationFlow"
+	DirectGrantFlowBinding          RealmFlowBinding = "directGrantFlow"
+	ResetCredentialsFlowBinding     RealmFlowBinding = "resetCredentialsFlow"
+	ClientAuthenticationFlowBinding RealmFlowBinding = "clientAuthenticationFlow"
+	DockerAuthenticationFlowBinding RealmFlowBinding = "dockerAuthenticationFlow"
+	FirstBrokerLoginFlowBinding     RealmFlowBinding = "firstBrokerLoginFlow"
+)
+
+// ClientFlowBinding is a flow of a client that can be overridden.
+type ClientFlowBinding string
+
+// Client flow binding overrides.
+const (
+	BrowserClientFlowBinding     ClientFlowBinding = "browser"
+	DirectGrantClientFlowBinding ClientFlowBinding = "direct_grant"
+)
+
+// AuthenticationFlow represents a Keycloak authentication flow(https://www.keycloak.org/docs-api/latest/rest-api/index.html#AuthenticationFlowRepresentation).
+type AuthenticationFlow struct {
+	ID                       *string                          `json:"id,omitempty"`
+	Alias                    *string                          `json:"alias,omitempty"`
+	Description              *string                          `json:"description,omitempty"`
+	ProviderID               *string                          `json:"providerId,omitempty"`
+	TopLevel                 *bool                            `json:"topLevel,omitempty"`
+	BuiltIn                  *bool                            `json:"builtIn,omitempty"`
+	AuthenticationExecutions *[]AuthenticationExecutionExport `json:"authenticationExecutions,omitempty"`
+}
+
+// AuthenticationExecutionExport represents an execution of an authentication flow as part of the flow(https://www.keycloak.org/docs-api/latest/rest-api/index.html#AuthenticationExecutionExportRepresentation).
+type AuthenticationExecutionExport struct {
+	Authenticator       *string `json:"authenticator,omitempty"`
+	AuthenticatorConfig *string `json:"authenticatorConfig,omitempty"`
+	AuthenticatorFlow   *bool   `json:"authenticatorFlow,omitempty"`
+	FlowAlias           *string `json:"flowAlias,omitempty"`
+	Priority            *int32  `json:"priority,omitempty"`
+	Requirement         *string `json:"requirement,omitempty"`
+	UserSetupAllowed    *bool   `json:"userSetupAllowed,omitempty"`
+}
+
+// AuthenticationExecutionInfo represents an execution of an authentication flow(https://www.keycloak.org/docs-api/latest/rest-api/index.html#AuthenticationExecutionInfoRepresentation).
+type AuthenticationExecutionInfo struct {
+	ID                   *string   `json:"id,omitempty"`
+	Alias                *string   `json:"alias,omitempty"`
+	AuthenticationConfig *string   `json:"authenticationConfig,omitempty"`
+	AuthenticationFlow   *bool     `json:"authenticationFlow,omitempty"`
+	Configurable         *bool     `json:"configurable,omitempty"`
+	Description          *string   `json:"description,omitempty"`
+	DisplayName          *string   `json:"displayName,omitempty"`
+	FlowID               *string   `json:"flowId,omitempty"`
+	Index                *int32    `json:"index,omitempty"`
+	Level                *int32    `json:"level,omitempty"`
+	Priority             *int32    `json:"priority,omitempty"`
+	ProviderID           *string   `json:"providerId,omitempty"`
+	Requirement          *string   `json:"requirement,omitempty"`
+	RequirementChoices   *[]string `json:"requirementChoices,omitempty"`
+}
+
+// AuthenticatorConfig represents the configuration of an authentication execution(https://www.keycloak.org/docs-api/latest/rest-api/index.html#AuthenticatorConfigRepresentation).
+type AuthenticatorConfig struct {
+	ID     *string            `json:"id,omitempty"`
+	Alias  *string            `json:"alias,omitempty"`
+	Config *map[string]string `json:"config,omitempty"`
+}
+
+// GetAuthenticationFlows returns the top-level authentication flows of the realm.
+func (a *AdminClient) GetAuthenticationFlows(ctx context.Context, realm string) ([]AuthenticationFlow, error) {
+	var flows []AuthenticationFlow
+	if err := a.doRequest(ctx, http.MethodGet, authenticationPath(realm)+"/flows", nil, &flows); err != nil {
+		return nil, err
+	}
+	return flows, nil
+}
+
+// GetAuthenticationFlow returns the authentication flow with the given id.
+func (a *AdminClient) GetAuthenticationFlow(ctx context.Context, realm, id string) (*AuthenticationFlow, error) {
+	var flow AuthenticationFlow
+	if err := a.doRequest(ctx, http.MethodGet, authenticationPath(realm)+"/flows/"+url.PathEscape(id), nil, &flow); err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+// CreateAuthenticationFlow creates a top-level authentication flow and returns its id.
+func (a *AdminClient) CreateAuthenticationFlow(ctx context.Context, realm string, flow AuthenticationFlow) (string, error) {
+	return a.createResource(ctx, authenticationPath(realm)+"/flows", flow)
+}
+
+// CopyAuthenticationFlow copies the authentication flow with the given alias, including its executions
+// and sub-flows, to a new flow named newAlias and returns the id of the copy.
+func (a *AdminClient) CopyAuthenticationFlow(ctx context.Context, realm, alias, newAlias string) (string, error) {
+	return a.createResource(ctx, flowPath(realm, alias)+"/copy", map[string]string{"newName": newAlias})
+}
+
+// DeleteAuthenticationFlow deletes the authentication flow with the given id.
+func (a *AdminClient) DeleteAuthenticationFlow(ctx context.Context, realm, id string) error {
+	return a.doRequest(ctx, http.MethodDelete, authenticationPath(realm)+"/flows/"+url.PathEscape(id), nil, nil)
+}
+
+// GetAuthenticationExecutions returns the executions of the authentication flow with the given alias,
+// including the executions of its sub-flows.
+func (a *AdminClient) GetAuthenticationExecutions(ctx context.Context, realm, flowAlias string) ([]AuthenticationExecutionInfo, error) {
+	var executions []AuthenticationExecutionInfo
+	if err := a.doRequest(ctx, http.MethodGet, flowPath(realm, flowAlias)+"/executions", nil, &executions); err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+// AddAuthenticationExecution adds an execution of the authenticator with the given provider id,
+// e.g. auth-otp-form, to the flow with the given alias and returns the id of the execution.
+// The execution is added with DISABLED requirement at the lowest priority.
+func (a *AdminClient) AddAuthenticationExecution(ctx context.Context, realm, flowAlias, provider string) (string, error) {
+	return a.createResource(ctx, flowPath(realm, flowAlias)+"/executions/execution", map[string]string{"provider": provider})
+}
+
+// AddAuthenticationSubFlow adds a sub-flow of the given type, BasicFlow or FormFlow, to the flow
+// with the given alias and returns the id of the execution that represents the sub-flow in its parent,
+// which is the id DeleteAuthenticationExecution, SetAuthenticationExecutionRequirement and the priority methods take.
+// Executions are added to the sub-flow by its alias with AddAuthenticationExecution.
+func (a *AdminClient) AddAuthenticationSubFlow(ctx context.Context, realm, flowAlias, alias, flowType string) (string, error) {
+	subFlow := map[string]string{
+		"alias": alias,
+		"type":  flowType,
+	}
+	if flowType == FormFlow {
+		subFlow["provider"] = formFlowProvider
+	}
+	flowID, err := a.createResource(ctx, flowPath(realm, flowAlias)+"/executions/flow", subFlow)
+	if err != nil {
+		return "", err
+	}
+
+	executions, err := a.GetAuthenticationExecutions(ctx, realm, flowAlias)
+	if err != nil {
+		return "", err
+	}
+	for _, execution := range executions {
+		if execution.ID != nil && execution.FlowID != nil && *execution.FlowID == flowID {
+			return *execution.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("execution of sub-flow %s not found in flow %s", alias, flowAlias)
+}
+
+// DeleteAuthenticationExecution removes the execution with the given id, or the sub-flow it represents, from its flow.
+func (a *AdminClient) DeleteAuthenticationExecution(ctx context.Context, realm, executionID string) error {
+	return a.doRequest(ctx, http.MethodDelete, executionPath(realm, executionID), nil, nil)
+}
+
+// SetAuthenticationExecutionRequirement changes the requirement of the execution with the given id
+// in the flow with the given alias, e.g. to RequirementRequired.
+func (a *AdminClient) SetAuthenticationExecutionRequirement(ctx context.Context, realm, flowAlias, executionID, requirement string) error {
+	executions, err := a.GetAuthenticationExecutions(ctx, realm, flowAlias)
+	if err != nil {
+		return err
+	}
+
+	for _, execution := range executions {
+		if execution.ID != nil && *execution.ID == executionID {
+			execution.Requirement = &requirement
+			return a.doRequest(ctx, http.MethodPut, flowPath(realm, flowAlias)+"/executions", execution, nil)
+		}
+	}
+
+	return fmt.Errorf("execution %s not found in flow %s", executionID, flowAlias)
+}
+
+// RaiseAuthenticationExecutionPriority moves the execution with the given id one position up in its flow.
+func (a *AdminClient) RaiseAuthenticationExecutionPriority(ctx context.Context, realm, executionID string) error {
+	return a.doRequest(ctx, http.MethodPost, executionPath(realm, executionID)+"/raise-priority", nil, nil)
+}
+
+// LowerAuthenticationExecutionPriority moves the execution with the given id one position down in its flow.
+func (a *AdminClient) LowerAuthenticationExecutionPriority(ctx context.Context, realm, executionID string) error {
+	return a.doRequest(ctx, http.MethodPost, executionPath(realm, executionID)+"/lower-priority", nil, nil)
+}
+
+// CreateAuthenticatorConfig sets the configuration of the execution with the given id and returns the id of the configuration.
+func (a *AdminClient) CreateAuthenticatorConfig(ctx context.Context, realm, executionID string, config AuthenticatorConfig) (string, error) {
+	return a.createResource(ctx, executionPath(realm, executionID)+"/config", config)
+}
+
+// GetAuthenticatorConfig returns the authenticator configuration with the given id.
+func (a *AdminClient) GetAuthenticatorConfig(ctx context.Context, realm, id string) (*AuthenticatorConfig, error) {
+	var config AuthenticatorConfig
+	if err := a.doRequest(ctx, http.MethodGet, authenticatorConfigPath(realm, id), nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// UpdateAuthenticatorConfig updates the authenticator configuration identified by its id.
+func (a *AdminClient) UpdateAuthenticatorConfig(ctx context.Context, realm string, config AuthenticatorConfig) error {
+	if config.ID == nil {
+		return errors.New("authenticator config id must be provided")
+	}
+	return a.doRequest(ctx, http.MethodPut, authenticatorConfigPath(realm, *config.ID), config, nil)
+}
+
+// DeleteAuthenticatorConfig deletes the authenticator configuration with the given id.
+func (a *AdminClient) DeleteAuthenticatorConfig(ctx context.Context, realm, id string) error {
+	return a.doRequest(ctx, http.MethodDelete, authenticatorConfigPath(realm, id), nil, nil)
+}
+
+// BindRealmFlow binds the authentication flow with the given alias to the realm, e.g. as BrowserFlowBinding.
+func (a *AdminClient) BindRealmFlow(ctx context.Context, realm string, binding RealmFlowBinding, flowAlias string) error {
+	return a.doRequest(ctx, http.MethodPut, "/"+url.PathEscape(realm), map[string]string{
+		string(binding): flowAlias,
+	}, nil)
+}
+
+// BindClientFlow overrides the flow of the client with the given clientID by the authentication flow with the given id.
+// An empty flowID removes the override.
+func (a *AdminClient) BindClientFlow(ctx context.Context, realm, clientID string, binding ClientFlowBinding, flowID string) error {
+	c, err := a.GetClient(realm, clientID)
+	if err != nil {
+		return err
+	}
+
+	overrides := map[string]string{}
+	if c.AuthenticationFlowBindingOverrides != nil {
+		overrides = *c.AuthenticationFlowBindingOverrides
+	}
+	// Keycloak keeps overrides missing from the update, so an empty value is sent to remove one
+	overrides[string(binding)] = flowID
+	c.AuthenticationFlowBindingOverrides = &overrides
+
+	return a.UpdateClient(ctx, realm, *c)
+}
+
+func authenticationPath(realm string) string {
+	return "/" + url.PathEscape(realm) + "/authentication"
+}
+
+func flowPath(realm, alias string) string {
+	return authenticationPath(realm) + "/flows/" + url.PathEscape(alias)
+}
+
+func executionPath(realm, executionID string) string {
+	return authenticationPath(realm) + "/executions/" + url.PathEscape(executionID)
+}
+
+func authenticatorConfigPath(realm, id string) string {
+	return authenticationPath(realm) + "/config/" + url.PathEscape(id)
+}
@@ -0,0 +1,107 @@
+package keycloak
+
+import (
+	"context"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestAdminClient_AuthenticationFlows(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	flowID, err := adminClient.CopyAuthenticationFlow(ctx, realm, "browser", "browser-otp")
+	if err != nil {
+		t.Errorf("CopyAuthenticationFlow() error = %v", err)
+		return
+	}
+
+	subFlowExecutionID, err := adminClient.AddAuthenticationSubFlow(ctx, realm, "browser-otp", "otp-step", BasicFlow)
+	if err != nil || subFlowExecutionID == "" {
+		t.Errorf("AddAuthenticationSubFlow() error = %v", err)
+		return
+	}
+
+	if err = adminClient.SetAuthenticationExecutionRequirement(ctx, realm, "browser-otp", subFlowExecutionID, RequirementConditional); err != nil {
+		t.Errorf("SetAuthenticationExecutionRequirement() of the sub-flow error = %v", err)
+		return
+	}
+
+	executionID, err := adminClient.AddAuthenticationExecution(ctx, realm, "otp-step", "auth-otp-form")
+	if err != nil {
+		t.Errorf("AddAuthenticationExecution() error = %v", err)
+		return
+	}
+
+	if err = adminClient.SetAuthenticationExecutionRequirement(ctx, realm, "browser-otp", executionID, RequirementRequired); err != nil {
+		t.Errorf("SetAuthenticationExecutionRequirement() error = %v", err)
+		return
+	}
+
+	executions, err := adminClient.GetAuthenticationExecutions(ctx, realm, "browser-otp")
+	if err != nil {
+		t.Errorf("GetAuthenticationExecutions() error = %v", err)
+		return
+	}
+
+	found, subFlowFound := false, false
+	for _, execution := range executions {
+		if *execution.ID == executionID {
+			found = *execution.Requirement == RequirementRequired
+		}
+		if *execution.ID == subFlowExecutionID {
+			subFlowFound = *execution.AuthenticationFlow && *execution.Requirement == RequirementConditional
+		}
+	}
+	if !found || !subFlowFound {
+		t.Errorf("GetAuthenticationExecutions() = %v", executions)
+		return
+	}
+
+	if err = adminClient.BindClientFlow(ctx, realm, client, BrowserClientFlowBinding, flowID); err != nil {
+		t.Errorf("BindClientFlow() error = %v", err)
+		return
+	}
+
+	c, err := adminClient.GetClient(realm, client)
+	if err != nil || (*c.AuthenticationFlowBindingOverrides)[string(BrowserClientFlowBinding)] != flowID {
+		t.Errorf("GetClient() = %v, error = %v", c, err)
+		return
+	}
+
+	if err = adminClient.BindRealmFlow(ctx, realm, BrowserFlowBinding, "browser-otp"); err != nil {
+		t.Errorf("BindRealmFlow() error = %v", err)
+		return
+	}
+
+	if err = adminClient.BindRealmFlow(ctx, realm, BrowserFlowBinding, "browser"); err != nil {
+		t.Errorf("BindRealmFlow() error = %v", err)
+		return
+	}
+
+	if err = adminClient.BindClientFlow(ctx, realm, client, BrowserClientFlowBinding, ""); err != nil {
+		t.Errorf("BindClientFlow() error = %v", err)
+		return
+	}
+
+	if err = adminClient.DeleteAuthenticationFlow(ctx, realm, flowID); err != nil {
+		t.Errorf("DeleteAuthenticationFlow() error = %v", err)
+	}
+}
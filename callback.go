@@ -0,0 +1,110 @@
+package keycloak
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const keycloakHostCallbackPortsEnv = "KEYCLOAK_HOST_CALLBACK_PORTS"
+
+// WithHostCallbacks is option to expose the given host ports to KeycloakContainer,
+// so Keycloak can call back into servers started by the test, e.g. for redirect URIs,
+// back-channel logout or identity provider stand-ins.
+// Use GetHostCallbackURL to get the URL of such a server as seen from KeycloakContainer.
+func WithHostCallbacks(ports ...int) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if len(ports) == 0 {
+			return fmt.Errorf("no host ports to expose")
+		}
+
+		if err := testcontainers.WithHostPortAccess(ports...)(req); err != nil {
+			return err
+		}
+
+		exposed := strings.Split(req.Env[keycloakHostCallbackPortsEnv], ",")
+		for _, port := range ports {
+			exposed = append(exposed, strconv.Itoa(port))
+		}
+		req.Env[keycloakHostCallbackPortsEnv] = strings.Trim(strings.Join(exposed, ","), ",")
+
+		return nil
+	}
+}
+
+// WithHostCallbackURLs is like WithHostCallbacks, but takes the URLs of servers
+// started by the test, e.g. httptest.Server.URL, and exposes their ports.
+func WithHostCallbackURLs(hostURLs ...string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		ports := make([]int, 0, len(hostURLs))
+		for _, hostURL := range hostURLs {
+			port, err := urlPort(hostURL)
+			if err != nil {
+				return err
+			}
+			ports = append(ports, port)
+		}
+
+		return WithHostCallbacks(ports...)(req)
+	}
+}
+
+// GetHostCallbackURL returns the URL under which KeycloakContainer reaches the server
+// listening at hostURL on the host, e.g. http://127.0.0.1:41234/logout becomes
+// http://host.testcontainers.internal:41234/logout.
+// The port of hostURL must have been exposed with WithHostCallbacks or WithHostCallbackURLs.
+func (k *KeycloakContainer) GetHostCallbackURL(hostURL string) (string, error) {
+	u, err := url.Parse(hostURL)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := urlPort(hostURL)
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.Contains(k.hostCallbackPorts, port) {
+		return "", fmt.Errorf("host port %d is not exposed, use WithHostCallbacks", port)
+	}
+
+	u.Host = net.JoinHostPort(testcontainers.HostInternal, strconv.Itoa(port))
+
+	return u.String(), nil
+}
+
+func urlPort(rawURL string) (int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, err
+	}
+
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		default:
+			return 0, fmt.Errorf("no port in URL %q", rawURL)
+		}
+	}
+
+	return strconv.Atoi(port)
+}
+
+func parseHostCallbackPorts(env string) []int {
+	var ports []int
+	for _, p := range strings.Split(env, ",") {
+		if port, err := strconv.Atoi(p); err == nil {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
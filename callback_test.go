@@ -0,0 +1,97 @@
+package keycloak
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestKeycloakContainer_GetHostCallbackURL(t *testing.T) {
+	k := &KeycloakContainer{hostCallbackPorts: []int{41234}}
+
+	tests := []struct {
+		name    string
+		hostURL string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "ExposedPort",
+			hostURL: "http://127.0.0.1:41234/logout?x=1",
+			want:    "http://host.testcontainers.internal:41234/logout?x=1",
+		},
+		{
+			name:    "NotExposedPort",
+			hostURL: "http://127.0.0.1:41235/logout",
+			wantErr: true,
+		},
+		{
+			name:    "DefaultPort",
+			hostURL: "http://localhost/logout",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := k.GetHostCallbackURL(tt.hostURL)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetHostCallbackURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetHostCallbackURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeycloakContainer_WithHostCallbackURLs(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "callback")
+	}))
+	defer server.Close()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithHostCallbackURLs(server.URL),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	callbackURL, err := container.GetHostCallbackURL(server.URL)
+	if err != nil {
+		t.Errorf("GetHostCallbackURL() error = %v", err)
+		return
+	}
+
+	port := callbackURL[strings.LastIndex(callbackURL, ":")+1:]
+	_, out, err := container.Exec(ctx, []string{"bash", "-c",
+		"exec 3<>/dev/tcp/" + testcontainers.HostInternal + "/" + port +
+			" && printf 'GET / HTTP/1.0\\r\\n\\r\\n' >&3 && cat <&3"})
+	if err != nil {
+		t.Errorf("Exec() error = %v", err)
+		return
+	}
+
+	body, err := io.ReadAll(out)
+	if err != nil {
+		t.Errorf("ReadAll() error = %v", err)
+		return
+	}
+
+	if !strings.Contains(string(body), "callback") {
+		t.Errorf("Exec() output = %s", body)
+	}
+}
@@ -0,0 +1,293 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	openIDConnectProtocol = "openid-connect"
+
+	hardcodedClaimMapper  = "oidc-hardcoded-claim-mapper"
+	userAttributeMapper   = "oidc-usermodel-attribute-mapper"
+	audienceMapper        = "oidc-audience-mapper"
+	groupMembershipMapper = "oidc-group-membership-mapper"
+	realmRoleMapper       = "oidc-usermodel-realm-role-mapper"
+)
+
+// ClientScope represents a Keycloak client scope(https://www.keycloak.org/docs-api/latest/rest-api/index.html#ClientScopeRepresentation).
+type ClientScope struct {
+	ID              *string            `json:"id,omitempty"`
+	Name            *string            `json:"name,omitempty"`
+	Description     *string            `json:"description,omitempty"`
+	Protocol        *string            `json:"protocol,omitempty"`
+	Attributes      *map[string]string `json:"attributes,omitempty"`
+	ProtocolMappers *[]ProtocolMapper  `json:"protocolMappers,omitempty"`
+}
+
+// ProtocolMapper represents a Keycloak protocol mapper(https://www.keycloak.org/docs-api/latest/rest-api/index.html#ProtocolMapperRepresentation).
+type ProtocolMapper struct {
+	ID             *string            `json:"id,omitempty"`
+	Name           *string            `json:"name,omitempty"`
+	Protocol       *string            `json:"protocol,omitempty"`
+	ProtocolMapper *string            `json:"protocolMapper,omitempty"`
+	Config         *map[string]string `json:"config,omitempty"`
+}
+
+// NewHardcodedClaimMapper returns a mapper adding a claim with a fixed value to the tokens.
+func NewHardcodedClaimMapper(name, claim, value string) ProtocolMapper {
+	config := tokenClaimConfig(claim)
+	config["claim.value"] = value
+	return newProtocolMapper(name, hardcodedClaimMapper, config)
+}
+
+// NewUserAttributeMapper returns a mapper adding the value of a user attribute as claim to the tokens.
+func NewUserAttributeMapper(name, attribute, claim string) ProtocolMapper {
+	config := tokenClaimConfig(claim)
+	config["user.attribute"] = attribute
+	return newProtocolMapper(name, userAttributeMapper, config)
+}
+
+// NewAudienceMapper returns a mapper adding the client with the given clientID to the audience of the access token.
+func NewAudienceMapper(name, audienceClientID string) ProtocolMapper {
+	return newProtocolMapper(name, audienceMapper, map[string]string{
+		"included.client.audience":  audienceClientID,
+		"access.token.claim":        "true",
+		"introspection.token.claim": "true",
+	})
+}
+
+// NewGroupMembershipMapper returns a mapper adding the groups of the user as claim to the tokens.
+// If fullPath is true, groups are given as path, e.g. /parent/child.
+func NewGroupMembershipMapper(name, claim string, fullPath bool) ProtocolMapper {
+	config := tokenClaimConfig(claim)
+	config["full.path"] = strconv.FormatBool(fullPath)
+	delete(config, "jsonType.label")
+	return newProtocolMapper(name, groupMembershipMapper, config)
+}
+
+// NewRoleListMapper returns a mapper adding the realm roles of the user as multivalued claim to the tokens.
+func NewRoleListMapper(name, claim string) ProtocolMapper {
+	config := tokenClaimConfig(claim)
+	config["multivalued"] = "true"
+	return newProtocolMapper(name, realmRoleMapper, config)
+}
+
+// CreateClientScope creates a client scope in the realm and returns its id.
+// The protocol defaults to openid-connect.
+func (a *AdminClient) CreateClientScope(ctx context.Context, realm string, scope ClientScope) (string, error) {
+	if scope.Protocol == nil {
+		protocol := openIDConnectProtocol
+		scope.Protocol = &protocol
+	}
+	return a.createResource(ctx, clientScopesPath(realm), scope)
+}
+
+// GetClientScopes returns all client scopes of the realm.
+func (a *AdminClient) GetClientScopes(ctx context.Context, realm string) ([]ClientScope, error) {
+	var scopes []ClientScope
+	if err := a.doRequest(ctx, http.MethodGet, clientScopesPath(realm), nil, &scopes); err != nil {
+		return nil, err
+	}
+	return scopes, nil
+}
+
+// GetClientScope returns the client scope with the given id.
+func (a *AdminClient) GetClientScope(ctx context.Context, realm, id string) (*ClientScope, error) {
+	var scope ClientScope
+	if err := a.doRequest(ctx, http.MethodGet, clientScopesPath(realm)+"/"+url.PathEscape(id), nil, &scope); err != nil {
+		return nil, err
+	}
+	return &scope, nil
+}
+
+// GetClientScopeByName returns the client scope with the given name.
+func (a *AdminClient) GetClientScopeByName(ctx context.Context, realm, name string) (*ClientScope, error) {
+	scopes, err := a.GetClientScopes(ctx, realm)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range scopes {
+		if scope.Name != nil && *scope.Name == name {
+			return &scope, nil
+		}
+	}
+
+	return nil, fmt.Errorf("client scope %s not found", name)
+}
+
+// UpdateClientScope updates the client scope identified by its id.
+func (a *AdminClient) UpdateClientScope(ctx context.Context, realm string, scope ClientScope) error {
+	if scope.ID == nil {
+		return errors.New("client scope id must be provided")
+	}
+	return a.doRequest(ctx, http.MethodPut, clientScopesPath(realm)+"/"+url.PathEscape(*scope.ID), scope, nil)
+}
+
+// DeleteClientScope deletes the client scope with the given id.
+func (a *AdminClient) DeleteClientScope(ctx context.Context, realm, id string) error {
+	return a.doRequest(ctx, http.MethodDelete, clientScopesPath(realm)+"/"+url.PathEscape(id), nil, nil)
+}
+
+// AddDefaultClientScope assigns the client scope with the given id as default scope to the client with the given clientID.
+func (a *AdminClient) AddDefaultClientScope(ctx context.Context, realm, clientID, scopeID string) error {
+	return a.clientScopeAssignment(ctx, http.MethodPut, realm, clientID, "default-client-scopes", scopeID)
+}
+
+// RemoveDefaultClientScope removes the client scope with the given id from the default scopes of the client.
+func (a *AdminClient) RemoveDefaultClientScope(ctx context.Context, realm, clientID, scopeID string) error {
+	return a.clientScopeAssignment(ctx, http.MethodDelete, realm, clientID, "default-client-scopes", scopeID)
+}
+
+// AddOptionalClientScope assigns the client scope with the given id as optional scope to the client with the given clientID.
+func (a *AdminClient) AddOptionalClientScope(ctx context.Context, realm, clientID, scopeID string) error {
+	return a.clientScopeAssignment(ctx, http.MethodPut, realm, clientID, "optional-client-scopes", scopeID)
+}
+
+// RemoveOptionalClientScope removes the client scope with the given id from the optional scopes of the client.
+func (a *AdminClient) RemoveOptionalClientScope(ctx context.Context, realm, clientID, scopeID string) error {
+	return a.clientScopeAssignment(ctx, http.MethodDelete, realm, clientID, "optional-client-scopes", scopeID)
+}
+
+// AddRealmDefaultClientScope makes the client scope with the given id a default scope of clients created in the realm.
+func (a *AdminClient) AddRealmDefaultClientScope(ctx context.Context, realm, scopeID string) error {
+	return a.doRequest(ctx, http.MethodPut, "/"+url.PathEscape(realm)+"/default-default-client-scopes/"+url.PathEscape(scopeID), nil, nil)
+}
+
+// RemoveRealmDefaultClientScope removes the client scope with the given id from the default scopes of the realm.
+func (a *AdminClient) RemoveRealmDefaultClientScope(ctx context.Context, realm, scopeID string) error {
+	return a.doRequest(ctx, http.MethodDelete, "/"+url.PathEscape(realm)+"/default-default-client-scopes/"+url.PathEscape(scopeID), nil, nil)
+}
+
+// AddRealmOptionalClientScope makes the client scope with the given id an optional scope of clients created in the realm.
+func (a *AdminClient) AddRealmOptionalClientScope(ctx context.Context, realm, scopeID string) error {
+	return a.doRequest(ctx, http.MethodPut, "/"+url.PathEscape(realm)+"/default-optional-client-scopes/"+url.PathEscape(scopeID), nil, nil)
+}
+
+// RemoveRealmOptionalClientScope removes the client scope with the given id from the optional scopes of the realm.
+func (a *AdminClient) RemoveRealmOptionalClientScope(ctx context.Context, realm, scopeID string) error {
+	return a.doRequest(ctx, http.MethodDelete, "/"+url.PathEscape(realm)+"/default-optional-client-scopes/"+url.PathEscape(scopeID), nil, nil)
+}
+
+// CreateClientProtocolMapper adds the protocol mapper to the client with the given clientID and returns the id of the mapper.
+func (a *AdminClient) CreateClientProtocolMapper(ctx context.Context, realm, clientID string, mapper ProtocolMapper) (string, error) {
+	path, err := a.clientPath(realm, clientID)
+	if err != nil {
+		return "", err
+	}
+	return a.createResource(ctx, path+"/protocol-mappers/models", mapper)
+}
+
+// GetClientProtocolMappers returns the protocol mappers of the client with the given clientID.
+func (a *AdminClient) GetClientProtocolMappers(ctx context.Context, realm, clientID string) ([]ProtocolMapper, error) {
+	path, err := a.clientPath(realm, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var mappers []ProtocolMapper
+	if err = a.doRequest(ctx, http.MethodGet, path+"/protocol-mappers/models", nil, &mappers); err != nil {
+		return nil, err
+	}
+	return mappers, nil
+}
+
+// UpdateClientProtocolMapper updates the protocol mapper of the client identified by its id.
+func (a *AdminClient) UpdateClientProtocolMapper(ctx context.Context, realm, clientID string, mapper ProtocolMapper) error {
+	if mapper.ID == nil {
+		return errors.New("protocol mapper id must be provided")
+	}
+
+	path, err := a.clientPath(realm, clientID)
+	if err != nil {
+		return err
+	}
+	return a.doRequest(ctx, http.MethodPut, path+"/protocol-mappers/models/"+url.PathEscape(*mapper.ID), mapper, nil)
+}
+
+// DeleteClientProtocolMapper removes the protocol mapper with the given id from the client.
+func (a *AdminClient) DeleteClientProtocolMapper(ctx context.Context, realm, clientID, id string) error {
+	path, err := a.clientPath(realm, clientID)
+	if err != nil {
+		return err
+	}
+	return a.doRequest(ctx, http.MethodDelete, path+"/protocol-mappers/models/"+url.PathEscape(id), nil, nil)
+}
+
+// CreateClientScopeProtocolMapper adds the protocol mapper to the client scope with the given id and returns the id of the mapper.
+func (a *AdminClient) CreateClientScopeProtocolMapper(ctx context.Context, realm, scopeID string, mapper ProtocolMapper) (string, error) {
+	return a.createResource(ctx, clientScopeMappersPath(realm, scopeID), mapper)
+}
+
+// GetClientScopeProtocolMappers returns the protocol mappers of the client scope with the given id.
+func (a *AdminClient) GetClientScopeProtocolMappers(ctx context.Context, realm, scopeID string) ([]ProtocolMapper, error) {
+	var mappers []ProtocolMapper
+	if err := a.doRequest(ctx, http.MethodGet, clientScopeMappersPath(realm, scopeID), nil, &mappers); err != nil {
+		return nil, err
+	}
+	return mappers, nil
+}
+
+// UpdateClientScopeProtocolMapper updates the protocol mapper of the client scope identified by its id.
+func (a *AdminClient) UpdateClientScopeProtocolMapper(ctx context.Context, realm, scopeID string, mapper ProtocolMapper) error {
+	if mapper.ID == nil {
+		return errors.New("protocol mapper id must be provided")
+	}
+	return a.doRequest(ctx, http.MethodPut, clientScopeMappersPath(realm, scopeID)+"/"+url.PathEscape(*mapper.ID), mapper, nil)
+}
+
+// DeleteClientScopeProtocolMapper removes the protocol mapper with the given id from the client scope.
+func (a *AdminClient) DeleteClientScopeProtocolMapper(ctx context.Context, realm, scopeID, id string) error {
+	return a.doRequest(ctx, http.MethodDelete, clientScopeMappersPath(realm, scopeID)+"/"+url.PathEscape(id), nil, nil)
+}
+
+func (a *AdminClient) clientScopeAssignment(ctx context.Context, method, realm, clientID, kind, scopeID string) error {
+	path, err := a.clientPath(realm, clientID)
+	if err != nil {
+		return err
+	}
+	return a.doRequest(ctx, method, path+"/"+kind+"/"+url.PathEscape(scopeID), nil, nil)
+}
+
+// clientPath resolves the internal id of the client with the given clientID and returns its admin API path.
+func (a *AdminClient) clientPath(realm, clientID string) (string, error) {
+	c, err := a.GetClient(realm, clientID)
+	if err != nil {
+		return "", err
+	}
+	return "/" + url.PathEscape(realm) + "/clients/" + url.PathEscape(*c.ID), nil
+}
+
+func clientScopesPath(realm string) string {
+	return "/" + url.PathEscape(realm) + "/client-scopes"
+}
+
+func clientScopeMappersPath(realm, scopeID string) string {
+	return clientScopesPath(realm) + "/" + url.PathEscape(scopeID) + "/protocol-mappers/models"
+}
+
+func newProtocolMapper(name, mapperType string, config map[string]string) ProtocolMapper {
+	protocol := openIDConnectProtocol
+	return ProtocolMapper{
+		Name:           &name,
+		Protocol:       &protocol,
+		ProtocolMapper: &mapperType,
+		Config:         &config,
+	}
+}
+
+func tokenClaimConfig(claim string) map[string]string {
+	return map[string]string{
+		"claim.name":                claim,
+		"jsonType.label":            "String",
+		"id.token.claim":            "true",
+		"access.token.claim":        "true",
+		"userinfo.token.claim":      "true",
+		"introspection.token.claim": "true",
+	}
+}
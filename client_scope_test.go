@@ -0,0 +1,174 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestAdminClient_ClientScopes(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	name := "tenant"
+	scopeID, err := adminClient.CreateClientScope(ctx, realm, ClientScope{Name: &name})
+	if err != nil {
+		t.Errorf("CreateClientScope() error = %v", err)
+		return
+	}
+
+	mapperID, err := adminClient.CreateClientScopeProtocolMapper(ctx, realm, scopeID, NewHardcodedClaimMapper("tenant", "tenant", "acme"))
+	if err != nil {
+		t.Errorf("CreateClientScopeProtocolMapper() error = %v", err)
+		return
+	}
+
+	if err = adminClient.AddDefaultClientScope(ctx, realm, client, scopeID); err != nil {
+		t.Errorf("AddDefaultClientScope() error = %v", err)
+		return
+	}
+
+	c, err := adminClient.GetClient(realm, client)
+	if err != nil || !slices.Contains(*c.DefaultClientScopes, name) {
+		t.Errorf("GetClient() = %v, error = %v", c, err)
+		return
+	}
+
+	scope, err := adminClient.GetClientScopeByName(ctx, realm, name)
+	if err != nil || *scope.ID != scopeID || len(*scope.ProtocolMappers) != 1 {
+		t.Errorf("GetClientScopeByName() = %v, error = %v", scope, err)
+		return
+	}
+
+	mapper := (*scope.ProtocolMappers)[0]
+	(*mapper.Config)["claim.value"] = "globex"
+	if err = adminClient.UpdateClientScopeProtocolMapper(ctx, realm, scopeID, mapper); err != nil {
+		t.Errorf("UpdateClientScopeProtocolMapper() error = %v", err)
+		return
+	}
+
+	clientMapperID, err := adminClient.CreateClientProtocolMapper(ctx, realm, client, NewAudienceMapper("audience", "broker"))
+	if err != nil {
+		t.Errorf("CreateClientProtocolMapper() error = %v", err)
+		return
+	}
+
+	mappers, err := adminClient.GetClientProtocolMappers(ctx, realm, client)
+	if err != nil || len(mappers) != 1 {
+		t.Errorf("GetClientProtocolMappers() = %v, error = %v", mappers, err)
+		return
+	}
+
+	// the mappers add their claims to the tokens issued for the client
+	err = adminClient.doRequest(ctx, http.MethodPost, "/"+realm+"/users", map[string]interface{}{
+		"username":      "scope-user",
+		"email":         "scope-user@example.com",
+		"firstName":     "Scope",
+		"lastName":      "User",
+		"enabled":       true,
+		"emailVerified": true,
+		"credentials": []map[string]interface{}{
+			{"type": "password", "value": "secret", "temporary": false},
+		},
+	}, nil)
+	if err != nil {
+		t.Errorf("create user error = %v", err)
+		return
+	}
+
+	claims, err := passwordGrantClaims(ctx, container, "scope-user", "secret")
+	if err != nil {
+		t.Errorf("password grant error = %v", err)
+		return
+	}
+	if claims["tenant"] != "globex" {
+		t.Errorf("tenant claim = %v, want globex", claims["tenant"])
+	}
+	if aud := audience(claims); !slices.Contains(aud, "broker") {
+		t.Errorf("aud = %v, want broker", aud)
+	}
+
+	if err = adminClient.DeleteClientProtocolMapper(ctx, realm, client, clientMapperID); err != nil {
+		t.Errorf("DeleteClientProtocolMapper() error = %v", err)
+		return
+	}
+
+	if err = adminClient.DeleteClientScopeProtocolMapper(ctx, realm, scopeID, mapperID); err != nil {
+		t.Errorf("DeleteClientScopeProtocolMapper() error = %v", err)
+		return
+	}
+
+	if err = adminClient.RemoveDefaultClientScope(ctx, realm, client, scopeID); err != nil {
+		t.Errorf("RemoveDefaultClientScope() error = %v", err)
+		return
+	}
+
+	if err = adminClient.DeleteClientScope(ctx, realm, scopeID); err != nil {
+		t.Errorf("DeleteClientScope() error = %v", err)
+	}
+}
+
+// passwordGrantClaims returns the verified claims of the access token issued to the user for the client.
+func passwordGrantClaims(ctx context.Context, container *KeycloakContainer, username, password string) (map[string]interface{}, error) {
+	cfg, err := container.GetOIDCConfig(ctx, realm, client)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.PostForm(cfg.TokenURL, url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"username":      {username},
+		"password":      {password},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("POST %s: unexpected status %d", cfg.TokenURL, resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	keysResp, err := http.Get(cfg.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+	defer keysResp.Body.Close()
+
+	var keys JSONWebKeySet
+	if err = json.NewDecoder(keysResp.Body).Decode(&keys); err != nil {
+		return nil, err
+	}
+
+	return verifyJWT(token.AccessToken, keys)
+}
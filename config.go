@@ -0,0 +1,314 @@
+package keycloak
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	keycloakConfigFormatEnv = "KEYCLOAK_CONFIG_FORMAT"
+	keycloakOptionsEnv      = "KEYCLOAK_OPTIONS"
+	keycloakConfigFile      = "/opt/keycloak/conf/keycloak.conf"
+)
+
+// ConfigFormat is the way the Config of KeycloakContainer is passed to Keycloak.
+// See https://www.keycloak.org/server/configuration#_configuration_sources
+type ConfigFormat string
+
+const (
+	// ConfigFormatArgs passes the options as command line arguments, e.g. --http-relative-path=/auth.
+	ConfigFormatArgs ConfigFormat = "args"
+	// ConfigFormatEnv passes the options as environment variables, e.g. KC_HTTP_RELATIVE_PATH=/auth.
+	ConfigFormatEnv ConfigFormat = "env"
+	// ConfigFormatFile passes the options in a keycloak.conf file mounted into /opt/keycloak/conf.
+	ConfigFormatFile ConfigFormat = "file"
+)
+
+// commandOnlyOptions are options of the start commands that have no configuration property,
+// so they are passed as command line arguments in every format.
+var commandOnlyOptions = []string{"import-realm", "optimized", "verbose"}
+
+// listOptions are options whose values are comma separated lists, which are merged when set more than once.
+var listOptions = []string{"features", "features-disabled"}
+
+// Config is the server configuration of KeycloakContainer, a set of options like http-relative-path
+// set by the options of Run. An option can only be set once, conflicting values are reported as error,
+// except for list options like features, whose values are merged.
+// See https://www.keycloak.org/server/all-config
+type Config struct {
+	// Command is the command Keycloak is started with, e.g. start-dev.
+	Command string
+	// Args are arguments that are not options, e.g. Java system properties like -Dfoo=bar.
+	Args []string
+
+	names  []string
+	values map[string]string
+}
+
+// NewConfig returns an empty Config for the given command.
+func NewConfig(command string) *Config {
+	return &Config{Command: command, values: make(map[string]string)}
+}
+
+// ParseConfig parses command line arguments like start-dev --http-relative-path=/auth into a Config.
+// Options must be given as --http-relative-path=/auth, an option without value is a flag like --import-realm.
+// Other arguments must be Java system properties like -Dfoo=bar, so a value given as separate argument,
+// e.g. --http-port 8081, is reported as error instead of being mistaken for a flag or a command.
+func ParseConfig(args []string) (*Config, error) {
+	c := NewConfig("")
+	for i, arg := range args {
+		name, isOption := strings.CutPrefix(arg, "--")
+		switch {
+		case isOption:
+			name, value, _ := strings.Cut(name, "=")
+			if err := c.Set(name, value); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(arg, "-D"):
+			c.Args = append(c.Args, arg)
+		case i == 0:
+			c.Command = arg
+		case i > 0 && strings.HasPrefix(args[i-1], "--") && !strings.Contains(args[i-1], "="):
+			return nil, fmt.Errorf("value %q of option %s must be given as %s=%s", arg, args[i-1], args[i-1], arg)
+		default:
+			return nil, fmt.Errorf("argument %q is neither an option like --name=value nor a system property like -Dname=value", arg)
+		}
+	}
+	return c, nil
+}
+
+// Set sets the option with the given name, without leading dashes, to value.
+// An empty value sets a flag like import-realm.
+func (c *Config) Set(name, value string) error {
+	if name == "" {
+		return errors.New("option name must not be empty")
+	}
+
+	current, ok := c.values[name]
+	switch {
+	case !ok:
+		c.names = append(c.names, name)
+		c.values[name] = value
+	case current == value:
+	case slices.Contains(listOptions, name):
+		c.values[name] = mergeList(current, value)
+	default:
+		return fmt.Errorf("conflicting values for option %s: %q and %q", name, current, value)
+	}
+
+	return nil
+}
+
+// Get returns the value of the option with the given name and whether it is set.
+func (c *Config) Get(name string) (string, bool) {
+	value, ok := c.values[name]
+	return value, ok
+}
+
+// Names returns the names of the set options in the order they were set.
+func (c *Config) Names() []string {
+	return slices.Clone(c.names)
+}
+
+// CommandLine returns the command, the options as command line arguments and the other arguments.
+func (c *Config) CommandLine() []string {
+	return c.commandLine(func(string) bool { return true })
+}
+
+// Env returns the options as KC_* environment variables.
+// Options without configuration property, like import-realm, are not included.
+func (c *Config) Env() map[string]string {
+	env := make(map[string]string)
+	for _, name := range c.names {
+		if !slices.Contains(commandOnlyOptions, name) {
+			env[configEnvName(name)] = configValue(c.values[name])
+		}
+	}
+	return env
+}
+
+// File returns the options in the keycloak.conf format.
+// Options without configuration property, like import-realm, are not included.
+func (c *Config) File() []byte {
+	var b strings.Builder
+	for _, name := range c.names {
+		if !slices.Contains(commandOnlyOptions, name) {
+			fmt.Fprintf(&b, "%s=%s\n", name, configValue(c.values[name]))
+		}
+	}
+	return []byte(b.String())
+}
+
+// WithConfigFormat is option to set how the configuration of KeycloakContainer is passed to Keycloak,
+// as command line arguments, which is the default, as KC_* environment variables or as keycloak.conf file.
+func WithConfigFormat(format ConfigFormat) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		switch format {
+		case ConfigFormatArgs, ConfigFormatEnv, ConfigFormatFile:
+		default:
+			return fmt.Errorf("unknown config format %q", format)
+		}
+		req.Env[keycloakConfigFormatEnv] = string(format)
+
+		return nil
+	}
+}
+
+// setOption sets an option of the Config carried with the request, so customizers fail on conflicting
+// options right away. Run merges them with the options of the command in requestConfig.
+func setOption(req *testcontainers.GenericContainerRequest, name, value string) error {
+	c, err := requestOptions(req)
+	if err != nil {
+		return err
+	}
+	if err = c.Set(name, value); err != nil {
+		return err
+	}
+
+	options, err := json.Marshal(c.CommandLine()[1:])
+	if err != nil {
+		return err
+	}
+	if req.Env == nil {
+		req.Env = make(map[string]string)
+	}
+	req.Env[keycloakOptionsEnv] = string(options)
+
+	return nil
+}
+
+// requestOptions returns the options set by setOption.
+func requestOptions(req *testcontainers.GenericContainerRequest) (*Config, error) {
+	var args []string
+	if options := req.Env[keycloakOptionsEnv]; options != "" {
+		if err := json.Unmarshal([]byte(options), &args); err != nil {
+			return nil, err
+		}
+	}
+	return ParseConfig(args)
+}
+
+// requestConfig returns the Config of the request, the command with the options added by customizers
+// outside of this package, like testcontainers.WithCmdArgs, and the options set by setOption.
+// The command is start-dev unless the request sets another one.
+func requestConfig(req *testcontainers.GenericContainerRequest) (*Config, error) {
+	c, err := ParseConfig(req.Cmd)
+	if err != nil {
+		return nil, err
+	}
+	if c.Command == "" {
+		c.Command = keycloakStartupCommand
+	}
+
+	options, err := requestOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range options.Names() {
+		value, _ := options.Get(name)
+		if err = c.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	delete(req.Env, keycloakOptionsEnv)
+
+	return c, nil
+}
+
+// applyConfig renders the Config of the request in the configured format.
+func applyConfig(req *testcontainers.GenericContainerRequest, c *Config) error {
+	var err error
+
+	// options set by the keycloak.conf of WithConfigFile are part of the Config but are not rendered again
+	rendered := c
+	configFile := req.Env[keycloakConfigFileEnv]
+	if configFile != "" {
+		if rendered, err = mergeConfigFile(c, configFile); err != nil {
+			return err
+		}
+	}
+
+	switch ConfigFormat(req.Env[keycloakConfigFormatEnv]) {
+	case ConfigFormatEnv:
+		for key, value := range rendered.Env() {
+			if current, ok := req.Env[key]; ok && current != value {
+				return fmt.Errorf("conflicting values for %s: %q and %q", key, current, value)
+			}
+			req.Env[key] = value
+		}
+		req.Cmd = rendered.commandOnly()
+	case ConfigFormatFile:
+		content := rendered.File()
+		if configFile != "" {
+			// the options are appended to the keycloak.conf of WithConfigFile, which is mounted as it is otherwise
+			if content, err = appendConfigFile(req, configFile, content); err != nil {
+				return err
+			}
+		}
+		req.Files = append(req.Files, testcontainers.ContainerFile{
+			Reader:            bytes.NewReader(content),
+			ContainerFilePath: keycloakConfigFile,
+			FileMode:          0o644,
+		})
+		req.Cmd = rendered.commandOnly()
+	default:
+		req.Cmd = rendered.CommandLine()
+	}
+
+	return nil
+}
+
+// commandOnly returns the command line with the options that have no configuration property only.
+func (c *Config) commandOnly() []string {
+	return c.commandLine(func(name string) bool { return slices.Contains(commandOnlyOptions, name) })
+}
+
+func (c *Config) commandLine(include func(name string) bool) []string {
+	args := []string{c.Command}
+	for _, name := range c.names {
+		if !include(name) {
+			continue
+		}
+		if value := c.values[name]; value != "" {
+			args = append(args, "--"+name+"="+value)
+		} else {
+			args = append(args, "--"+name)
+		}
+	}
+	return append(args, c.Args...)
+}
+
+// configEnvName returns the environment variable of an option, e.g. KC_HTTP_RELATIVE_PATH of http-relative-path.
+func configEnvName(name string) string {
+	return "KC_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// configValue returns the value of an option outside of the command line, where flags are spelled out.
+func configValue(value string) string {
+	if value == "" {
+		return "true"
+	}
+	return value
+}
+
+func mergeList(current, value string) string {
+	items := strings.Split(current, ",")
+	for _, item := range strings.Split(value, ",") {
+		if !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// Config returns the server configuration KeycloakContainer was started with,
+// or nil for legacy images, which are configured by environment variables.
+func (k *KeycloakContainer) Config() *Config {
+	return k.config
+}
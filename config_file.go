@@ -0,0 +1,239 @@
+package keycloak
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/log"
+)
+
+const (
+	keycloakConfigFileEnv = "KEYCLOAK_CONFIG_FILE"
+	keycloakHTTPPortEnv   = "KEYCLOAK_HTTP_PORT"
+	keycloakHTTPSPortEnv  = "KEYCLOAK_HTTPS_PORT"
+)
+
+// configFileOptions are the options keycloak.conf accepts besides SPI options like spi-theme-cache-themes
+// and log levels per category like log-level-org.keycloak.events.
+// See https://www.keycloak.org/server/all-config
+var configFileOptions = []string{
+	"bootstrap-admin-client-id", "bootstrap-admin-client-secret", "bootstrap-admin-password", "bootstrap-admin-username",
+	"cache", "cache-config-file", "cache-embedded-mtls-enabled", "cache-embedded-mtls-key-store-file",
+	"cache-embedded-mtls-key-store-password", "cache-embedded-mtls-trust-store-file",
+	"cache-embedded-mtls-trust-store-password", "cache-metrics-histograms-enabled", "cache-remote-host",
+	"cache-remote-password", "cache-remote-port", "cache-remote-tls-enabled", "cache-remote-username", "cache-stack",
+	"config-keystore", "config-keystore-password", "config-keystore-type",
+	"db", "db-driver", "db-password", "db-pool-initial-size", "db-pool-max-size", "db-pool-min-size", "db-schema",
+	"db-url", "db-url-database", "db-url-host", "db-url-port", "db-url-properties", "db-username",
+	"transaction-xa-enabled",
+	"event-metrics-user-enabled", "event-metrics-user-events", "event-metrics-user-tags",
+	"features", "features-disabled",
+	"fips-mode",
+	"health-enabled", "metrics-enabled",
+	"hostname", "hostname-admin", "hostname-backchannel-dynamic", "hostname-debug", "hostname-strict",
+	"hostname-admin-url", "hostname-path", "hostname-port", "hostname-strict-backchannel", "hostname-strict-https",
+	"hostname-url",
+	"http-access-log-enabled", "http-access-log-exclude", "http-access-log-pattern", "http-enabled", "http-host",
+	"http-management-port", "http-management-relative-path", "http-max-queued-requests",
+	"http-metrics-histograms-enabled", "http-metrics-slos", "http-pool-max-threads", "http-port", "http-relative-path",
+	"https-certificate-file", "https-certificate-key-file", "https-certificates-reload-period", "https-cipher-suites",
+	"https-client-auth", "https-key-store-file", "https-key-store-password", "https-key-store-type", "https-port",
+	"https-protocols", "https-trust-store-file", "https-trust-store-password", "https-trust-store-type",
+	"log", "log-async", "log-level",
+	"log-console-color", "log-console-format", "log-console-include-trace", "log-console-json-format",
+	"log-console-level", "log-console-output",
+	"log-file", "log-file-format", "log-file-include-trace", "log-file-json-format", "log-file-level",
+	"log-file-output",
+	"log-syslog-app-name", "log-syslog-enabled", "log-syslog-endpoint", "log-syslog-format",
+	"log-syslog-include-trace", "log-syslog-json-format", "log-syslog-level", "log-syslog-max-length",
+	"log-syslog-output", "log-syslog-protocol", "log-syslog-type",
+	"proxy", "proxy-headers", "proxy-protocol-enabled", "proxy-trusted-addresses",
+	"tls-hostname-verifier", "truststore-paths",
+	"tracing-compression", "tracing-enabled", "tracing-endpoint", "tracing-jdbc-enabled", "tracing-protocol",
+	"tracing-resource-attributes", "tracing-sampler-ratio", "tracing-sampler-type", "tracing-service-name",
+	"vault", "vault-dir", "vault-file", "vault-pass", "vault-type",
+}
+
+// configFileOptionPrefixes are the prefixes of options keycloak.conf accepts for any name.
+var configFileOptionPrefixes = []string{"spi-", "log-level-"}
+
+// ParseConfigFile parses a keycloak.conf file into a Config without command.
+// Values are kept as they are, including expressions like ${KC_DB_PASSWORD}.
+func ParseConfigFile(r io.Reader) (*Config, error) {
+	c := NewConfig("")
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing = in %q", line, text)
+		}
+		if err := c.Set(strings.TrimSpace(name), strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// WithConfigFile is option to mount a keycloak.conf file into /opt/keycloak/conf of KeycloakContainer.
+// The file is parsed to learn the context path, TLS, ports and hostname, so GetAuthServerURL
+// and the wait strategy follow it. Options Keycloak does not know, e.g. misspelled ones, are ignored by Keycloak,
+// Run reports them through the logger of the request.
+// Files the options refer to, e.g. the certificate of https-certificate-file, must be mounted separately.
+// Options of other customizers that conflict with the file are reported as error by Run.
+// See https://www.keycloak.org/server/configuration#_configuring_keycloak_using_the_keycloak_conf_file
+func WithConfigFile(configFile string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		c, err := readConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		req.Files = append(req.Files, testcontainers.ContainerFile{
+			HostFilePath:      configFile,
+			ContainerFilePath: keycloakConfigFile,
+			FileMode:          0o644,
+		})
+		req.Env[keycloakConfigFileEnv] = configFile
+
+		if contextPath, ok := c.Get("http-relative-path"); ok {
+			req.Env[keycloakContextPathEnv] = contextPath
+		}
+
+		_, certificate := c.Get("https-certificate-file")
+		_, keyStore := c.Get("https-key-store-file")
+		if certificate || keyStore {
+			req.Env[keycloakTlsEnv] = "true"
+		}
+		// the port is chosen by serverPort, since TLS may also be enabled by WithTLS before or after this option
+		for option, env := range map[string]string{"http-port": keycloakHTTPPortEnv, "https-port": keycloakHTTPSPortEnv} {
+			if value, ok := c.Get(option); ok {
+				if _, err = strconv.Atoi(value); err != nil {
+					return fmt.Errorf("invalid %s %q in %s", option, value, configFile)
+				}
+				req.Env[env] = value + "/tcp"
+			}
+		}
+		req.ExposedPorts = []string{serverPort(req)}
+
+		return nil
+	}
+}
+
+// mergeConfigFile adds the options of the keycloak.conf file to the Config and returns the Config
+// that still has to be rendered, without the options the file already sets to the same value.
+// List options are merged and kept, since command line arguments and environment variables
+// replace the value of the file.
+func mergeConfigFile(c *Config, configFile string) (*Config, error) {
+	file, err := readConfigFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := NewConfig(c.Command)
+	rendered.Args = c.Args
+	for _, name := range c.names {
+		value := c.values[name]
+		if fileValue, ok := file.values[name]; ok {
+			switch {
+			case slices.Contains(listOptions, name):
+				value = mergeList(fileValue, value)
+			case fileValue == value:
+				continue
+			default:
+				return nil, fmt.Errorf("conflicting values for option %s: %q in %s and %q", name, fileValue, configFile, value)
+			}
+		}
+		if err = rendered.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range file.names {
+		if err = c.Set(name, file.values[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	return rendered, nil
+}
+
+// configHostname returns the frontend URL pinned by the Config, if the hostname option is a full URL.
+// A bare hostname leaves the scheme, port and context path to the request, so there is none.
+func configHostname(c *Config) string {
+	if hostname, ok := c.Get("hostname-url"); ok {
+		return hostname
+	}
+	if hostname, ok := c.Get("hostname"); ok && strings.Contains(hostname, "://") {
+		return hostname
+	}
+	return ""
+}
+
+// appendConfigFile removes the keycloak.conf of WithConfigFile from the files of the request
+// and returns its content followed by the given options.
+func appendConfigFile(req *testcontainers.GenericContainerRequest, configFile string, options []byte) ([]byte, error) {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	req.Files = slices.DeleteFunc(req.Files, func(f testcontainers.ContainerFile) bool {
+		return f.ContainerFilePath == keycloakConfigFile && f.HostFilePath == configFile
+	})
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	return append(content, options...), nil
+}
+
+func readConfigFile(configFile string) (*Config, error) {
+	f, err := os.Open(configFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c, err := ParseConfigFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", configFile, err)
+	}
+	return c, nil
+}
+
+// logUnknownConfigFileOptions reports the options of the keycloak.conf file that are not in configFileOptions.
+// They are not an error, since configFileOptions may lag behind the Keycloak version of the image.
+func logUnknownConfigFileOptions(configFile string, logger log.Logger) error {
+	c, err := readConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+	if unknown := unknownConfigFileOptions(c); len(unknown) > 0 {
+		logger.Printf("unknown options in %s: %s", configFile, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func unknownConfigFileOptions(c *Config) []string {
+	var unknown []string
+	for _, name := range c.names {
+		if slices.Contains(configFileOptions, name) {
+			continue
+		}
+		if slices.ContainsFunc(configFileOptionPrefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) {
+			continue
+		}
+		unknown = append(unknown, name)
+	}
+	return unknown
+}
@@ -0,0 +1,200 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "options",
+			content: "# comment\n\nhttp-relative-path = /auth\ndb-password=${DB_PASSWORD:secret}\n",
+			want:    []string{"--http-relative-path=/auth", "--db-password=${DB_PASSWORD:secret}"},
+		},
+		{
+			name:    "missing value",
+			content: "http-relative-path\n",
+			wantErr: true,
+		},
+		{
+			name:    "conflict",
+			content: "http-port=8081\nhttp-port=8082\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseConfigFile(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := c.CommandLine()[1:]; fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("CommandLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithConfigFile(t *testing.T) {
+	newRequest := func(opts ...testcontainers.CustomizeRequestOption) (*testcontainers.GenericContainerRequest, error) {
+		req := &testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Env:          map[string]string{},
+				ExposedPorts: []string{keycloakPort},
+				Cmd:          []string{keycloakStartupCommand},
+			},
+		}
+		for _, opt := range opts {
+			if err := opt(req); err != nil {
+				return nil, err
+			}
+		}
+		c, err := requestConfig(req)
+		if err != nil {
+			return nil, err
+		}
+		return req, applyConfig(req, c)
+	}
+
+	withFeatures := func(req *testcontainers.GenericContainerRequest) error {
+		return setOption(req, "features", "admin-fine-grained-authz")
+	}
+
+	req, err := newRequest(WithConfigFile("testdata/keycloak.conf"), withFeatures)
+	if err != nil {
+		t.Errorf("WithConfigFile() error = %v", err)
+		return
+	}
+	if req.Env[keycloakContextPathEnv] != "/auth" || serverPort(req) != "8081/tcp" || fmt.Sprint(req.ExposedPorts) != "[8081/tcp]" {
+		t.Errorf("Env = %v, ExposedPorts = %v", req.Env, req.ExposedPorts)
+	}
+	if want := "[start-dev --features=token-exchange,admin-fine-grained-authz]"; fmt.Sprint(req.Cmd) != want {
+		t.Errorf("Cmd = %v, want %v", req.Cmd, want)
+	}
+
+	req, err = newRequest(WithConfigFile("testdata/keycloak.conf"), WithContextPath("/auth"), WithConfigFormat(ConfigFormatFile))
+	if err != nil {
+		t.Errorf("WithConfigFile() error = %v", err)
+		return
+	}
+	if len(req.Files) != 1 || req.Files[0].ContainerFilePath != keycloakConfigFile {
+		t.Errorf("Files = %v, want a single keycloak.conf", req.Files)
+		return
+	}
+	content, err := io.ReadAll(req.Files[0].Reader)
+	if err != nil {
+		t.Errorf("ReadAll() error = %v", err)
+		return
+	}
+	want, err := os.ReadFile("testdata/keycloak.conf")
+	if err != nil {
+		t.Errorf("ReadFile() error = %v", err)
+		return
+	}
+	if string(content) != string(want) {
+		t.Errorf("keycloak.conf = %q, want %q", content, want)
+	}
+
+	if _, err = newRequest(WithConfigFile("testdata/keycloak.conf"), WithContextPath("/")); err == nil {
+		t.Errorf("applyConfig() with conflicting context path error = nil")
+	}
+
+	// the http-port of the file does not apply once WithTLS enables https, whatever the order of the options
+	withTLS := WithTLS("testdata/tls.crt", "testdata/tls.key")
+	for _, opts := range [][]testcontainers.CustomizeRequestOption{
+		{WithConfigFile("testdata/keycloak.conf"), withTLS},
+		{withTLS, WithConfigFile("testdata/keycloak.conf")},
+	} {
+		if req, err = newRequest(opts...); err != nil {
+			t.Errorf("WithConfigFile() with WithTLS error = %v", err)
+			return
+		}
+		if serverPort(req) != keycloakHttpsPort || fmt.Sprint(req.ExposedPorts) != "["+keycloakHttpsPort+"]" {
+			t.Errorf("WithConfigFile() with WithTLS port = %v, ExposedPorts = %v", serverPort(req), req.ExposedPorts)
+		}
+	}
+
+	httpsPort := filepath.Join(t.TempDir(), "keycloak.conf")
+	if err = os.WriteFile(httpsPort, []byte("http-port=8081\nhttps-port=8444\n"), 0o644); err != nil {
+		t.Errorf("WriteFile() error = %v", err)
+		return
+	}
+	if req, err = newRequest(withTLS, WithConfigFile(httpsPort)); err != nil {
+		t.Errorf("WithConfigFile() with https-port error = %v", err)
+		return
+	}
+	if serverPort(req) != "8444/tcp" || fmt.Sprint(req.ExposedPorts) != "[8444/tcp]" {
+		t.Errorf("WithConfigFile() with https-port port = %v, ExposedPorts = %v", serverPort(req), req.ExposedPorts)
+	}
+
+	unknown := filepath.Join(t.TempDir(), "keycloak.conf")
+	if err = os.WriteFile(unknown, []byte("http-relative-path=/auth\nhttp-relativ-path=/\nspi-theme-cache-themes=false\n"), 0o644); err != nil {
+		t.Errorf("WriteFile() error = %v", err)
+		return
+	}
+	if _, err = newRequest(WithConfigFile(unknown)); err != nil {
+		t.Errorf("WithConfigFile() with unknown option error = %v", err)
+	}
+	var logger recordingLogger
+	if err = logUnknownConfigFileOptions(unknown, &logger); err != nil {
+		t.Errorf("logUnknownConfigFileOptions() error = %v", err)
+		return
+	}
+	if len(logger) != 1 || !strings.Contains(logger[0], "http-relativ-path") || strings.Contains(logger[0], "spi-theme") {
+		t.Errorf("logUnknownConfigFileOptions() logged %v", logger)
+	}
+}
+
+func TestKeycloakContainer_WithConfigFile(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithConfigFile("testdata/keycloak.conf"),
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	authServerURL, err := container.GetAuthServerURL(ctx)
+	if err != nil {
+		t.Errorf("GetAuthServerURL() error = %v", err)
+		return
+	}
+	if !strings.HasSuffix(authServerURL, "/auth") {
+		t.Errorf("GetAuthServerURL() = %v, want context path /auth", authServerURL)
+	}
+
+	resp, err := http.Get(authServerURL + "/realms/" + realm + "/.well-known/openid-configuration")
+	if err != nil {
+		t.Errorf("http.Get() error = %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("http.Get() status = %v", resp.StatusCode)
+	}
+}
@@ -0,0 +1,240 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "options and flags",
+			args: []string{"start-dev", "--http-relative-path=/auth", "--import-realm", "-Dfoo=bar"},
+			want: []string{"start-dev", "--http-relative-path=/auth", "--import-realm", "-Dfoo=bar"},
+		},
+		{
+			name: "flags",
+			args: []string{"start", "--optimized", "--verbose", "--health-enabled", "-Dfoo=bar"},
+			want: []string{"start", "--optimized", "--verbose", "--health-enabled", "-Dfoo=bar"},
+		},
+		{
+			name: "values starting with dashes",
+			args: []string{"start-dev", "--spi-theme-static-max-age=-1"},
+			want: []string{"start-dev", "--spi-theme-static-max-age=-1"},
+		},
+		{
+			name:    "separate value",
+			args:    []string{"start-dev", "--http-port", "8081"},
+			wantErr: true,
+		},
+		{
+			name:    "separate value starting with a dash",
+			args:    []string{"start-dev", "--spi-theme-static-max-age", "-1"},
+			wantErr: true,
+		},
+		{
+			name: "duplicates",
+			args: []string{"start-dev", "--import-realm", "--http-relative-path=/auth", "--import-realm", "--http-relative-path=/auth"},
+			want: []string{"start-dev", "--import-realm", "--http-relative-path=/auth"},
+		},
+		{
+			name: "merged features",
+			args: []string{"start-dev", "--features=token-exchange", "--features=admin-fine-grained-authz,token-exchange"},
+			want: []string{"start-dev", "--features=token-exchange,admin-fine-grained-authz"},
+		},
+		{
+			name:    "conflict",
+			args:    []string{"start-dev", "--http-relative-path=/auth", "--http-relative-path=/"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseConfig(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := c.CommandLine(); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("CommandLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestConfig(t *testing.T) {
+	newRequest := func(cmd ...string) *testcontainers.GenericContainerRequest {
+		return &testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Env: map[string]string{},
+				Cmd: cmd,
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		req     *testcontainers.GenericContainerRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "default command",
+			req:  newRequest(),
+			want: []string{keycloakStartupCommand, "--http-relative-path=/auth"},
+		},
+		{
+			name: "command of the request",
+			req:  newRequest("start", "--optimized"),
+			want: []string{"start", "--optimized", "--http-relative-path=/auth"},
+		},
+		{
+			name: "options without command",
+			req:  newRequest("--health-enabled=true"),
+			want: []string{keycloakStartupCommand, "--health-enabled=true", "--http-relative-path=/auth"},
+		},
+		{
+			name:    "conflict with the command",
+			req:     newRequest(keycloakStartupCommand, "--http-relative-path=/"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := WithContextPath("/auth")(tt.req); err != nil {
+				t.Errorf("WithContextPath() error = %v", err)
+				return
+			}
+			c, err := requestConfig(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("requestConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := c.CommandLine(); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("CommandLine() = %v, want %v", got, tt.want)
+			}
+			if _, ok := tt.req.Env[keycloakOptionsEnv]; ok {
+				t.Errorf("Env = %v, want the options merged", tt.req.Env)
+			}
+		})
+	}
+
+	req := newRequest()
+	if err := WithContextPath("/auth")(req); err != nil {
+		t.Errorf("WithContextPath() error = %v", err)
+		return
+	}
+	if err := WithContextPath("/")(req); err == nil {
+		t.Errorf("WithContextPath() with conflicting context path error = nil")
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	newRequest := func(format ConfigFormat) *testcontainers.GenericContainerRequest {
+		req := &testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Env: map[string]string{},
+				Cmd: []string{keycloakStartupCommand},
+			},
+		}
+		for _, opt := range []testcontainers.CustomizeRequestOption{
+			WithConfigFormat(format),
+			WithRealmImportFile("testdata/realm-export.json"),
+			WithContextPath("/auth"),
+			WithLogLevels(LogLevelInfo, map[string]LogLevel{"org.keycloak.events": LogLevelDebug}),
+		} {
+			if err := opt(req); err != nil {
+				t.Fatal(err)
+			}
+		}
+		c, err := requestConfig(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = applyConfig(req, c); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	req := newRequest(ConfigFormatArgs)
+	if want := "[start-dev --import-realm --http-relative-path=/auth --log-level=info,org.keycloak.events:debug]"; fmt.Sprint(req.Cmd) != want {
+		t.Errorf("args: Cmd = %v, want %v", req.Cmd, want)
+	}
+
+	req = newRequest(ConfigFormatEnv)
+	if want := "[start-dev --import-realm]"; fmt.Sprint(req.Cmd) != want {
+		t.Errorf("env: Cmd = %v, want %v", req.Cmd, want)
+	}
+	if req.Env["KC_HTTP_RELATIVE_PATH"] != "/auth" || req.Env["KC_LOG_LEVEL"] != "info,org.keycloak.events:debug" {
+		t.Errorf("env: Env = %v", req.Env)
+	}
+
+	req = newRequest(ConfigFormatFile)
+	if want := "[start-dev --import-realm]"; fmt.Sprint(req.Cmd) != want {
+		t.Errorf("file: Cmd = %v, want %v", req.Cmd, want)
+	}
+	f := req.Files[len(req.Files)-1]
+	content, err := io.ReadAll(f.Reader)
+	if err != nil {
+		t.Errorf("ReadAll() error = %v", err)
+		return
+	}
+	if want := "http-relative-path=/auth\nlog-level=info,org.keycloak.events:debug\n"; f.ContainerFilePath != keycloakConfigFile || string(content) != want {
+		t.Errorf("file: %s = %q, want %q", f.ContainerFilePath, content, want)
+	}
+}
+
+func TestKeycloakContainer_WithConfigFormat(t *testing.T) {
+	ctx := context.Background()
+
+	for _, format := range []ConfigFormat{ConfigFormatEnv, ConfigFormatFile} {
+		t.Run(string(format), func(t *testing.T) {
+			container, err := Run(ctx,
+				"keycloak/keycloak:26.0",
+				WithConfigFormat(format),
+				WithContextPath("/auth"),
+				WithRealmImportFile("testdata/realm-export.json"),
+			)
+			if err != nil {
+				t.Errorf("Run() error = %v", err)
+				return
+			}
+
+			testcontainers.CleanupContainer(t, container)
+
+			authServerURL, err := container.GetAuthServerURL(ctx)
+			if err != nil {
+				t.Errorf("GetAuthServerURL() error = %v", err)
+				return
+			}
+
+			resp, err := http.Get(authServerURL + "/realms/" + realm + "/.well-known/openid-configuration")
+			if err != nil {
+				t.Errorf("http.Get() error = %v", err)
+				return
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("http.Get() status = %v", resp.StatusCode)
+			}
+		})
+	}
+}
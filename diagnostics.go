@@ -0,0 +1,104 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const (
+	diagnosticsTimeout   = time.Minute
+	diagnosticsMaxEvents = 100
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// DumpOnFailure registers a cleanup on t that, only if the test failed, writes diagnostics of
+// KeycloakContainer to a directory named after the test below dir: the container log, a partial
+// export of each of the given realms, their most recent events and admin events, and the server info.
+// Cleanups run in reverse order, so DumpOnFailure must be called after testcontainers.CleanupContainer
+// for the diagnostics to be written before the container is terminated.
+// Failures to collect single artifacts are logged and do not stop the others from being written.
+func (k *KeycloakContainer) DumpOnFailure(t testing.TB, dir string, realms ...string) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+		defer cancel()
+
+		artifactsDir := filepath.Join(dir, unsafeFileNameChars.ReplaceAllString(t.Name(), "_"))
+		for _, err := range k.dumpDiagnostics(ctx, artifactsDir, realms) {
+			t.Logf("keycloak diagnostics: %v", err)
+		}
+		t.Logf("keycloak diagnostics written to %s", artifactsDir)
+	})
+}
+
+func (k *KeycloakContainer) dumpDiagnostics(ctx context.Context, dir string, realms []string) []error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	// write stores v as file, raw if it is a byte slice and as indented JSON otherwise
+	write := func(name string, v interface{}, err error) {
+		if err == nil {
+			data, ok := v.([]byte)
+			if !ok {
+				data, err = json.MarshalIndent(v, "", "  ")
+			}
+			if err == nil {
+				err = os.WriteFile(filepath.Join(dir, name), data, 0o644)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	logs, err := k.readLogs(ctx)
+	write("keycloak.log", logs, err)
+
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return append(errs, fmt.Errorf("admin client: %w", err))
+	}
+
+	serverInfo, err := adminClient.getRawServerInfo(ctx)
+	write("server-info.json", serverInfo, err)
+
+	for _, realm := range realms {
+		name := unsafeFileNameChars.ReplaceAllString(realm, "_")
+
+		export, err := adminClient.PartialExport(ctx, realm, PartialExportOptions{GroupsAndRoles: true, Clients: true})
+		write("realm-"+name+".json", json.RawMessage(export), err)
+
+		events, err := adminClient.GetEvents(ctx, realm, EventQuery{Max: diagnosticsMaxEvents})
+		write("events-"+name+".json", events, err)
+
+		adminEvents, err := adminClient.GetAdminEvents(ctx, realm, AdminEventQuery{Max: diagnosticsMaxEvents})
+		write("admin-events-"+name+".json", adminEvents, err)
+	}
+
+	return errs
+}
+
+func (k *KeycloakContainer) readLogs(ctx context.Context) ([]byte, error) {
+	rc, err := k.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
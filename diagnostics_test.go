@@ -0,0 +1,49 @@
+package keycloak
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestKeycloakContainer_DumpOnFailure(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	dir := t.TempDir()
+	for _, failed := range []bool{false, true} {
+		tb := &recordingTB{TB: t, failed: failed}
+		container.DumpOnFailure(tb, dir, realm)
+		tb.runCleanups()
+		for _, log := range tb.lines {
+			if strings.HasPrefix(log, "keycloak diagnostics: ") {
+				t.Errorf("DumpOnFailure() error = %s", log)
+			}
+		}
+
+		artifactsDir := filepath.Join(dir, "TestKeycloakContainer_DumpOnFailure")
+		for _, name := range []string{"keycloak.log", "server-info.json", "realm-Test.json", "events-Test.json", "admin-events-Test.json"} {
+			_, err := os.Stat(filepath.Join(artifactsDir, name))
+			if failed && err != nil {
+				t.Errorf("%s not written: %v", name, err)
+			}
+			if !failed && err == nil {
+				t.Errorf("%s written although the test passed", name)
+			}
+		}
+	}
+}
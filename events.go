@@ -0,0 +1,328 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	eventsDateLayout   = "2006-01-02"
+	eventsPollInterval = 500 * time.Millisecond
+	eventsPollMax      = 100
+	// eventsPollRetries is the number of consecutive failed polls after which SubscribeEvents gives up
+	eventsPollRetries = 10
+)
+
+// RealmEventsConfig represents the event configuration of a realm(https://www.keycloak.org/docs-api/latest/rest-api/index.html#RealmEventsConfigRepresentation).
+type RealmEventsConfig struct {
+	EventsEnabled             *bool     `json:"eventsEnabled,omitempty"`
+	EventsExpiration          *int64    `json:"eventsExpiration,omitempty"`
+	EventsListeners           *[]string `json:"eventsListeners,omitempty"`
+	EnabledEventTypes         *[]string `json:"enabledEventTypes,omitempty"`
+	AdminEventsEnabled        *bool     `json:"adminEventsEnabled,omitempty"`
+	AdminEventsDetailsEnabled *bool     `json:"adminEventsDetailsEnabled,omitempty"`
+}
+
+// Event represents a Keycloak login event(https://www.keycloak.org/docs-api/latest/rest-api/index.html#EventRepresentation).
+type Event struct {
+	ID        string            `json:"id,omitempty"`
+	Time      int64             `json:"time"`
+	Type      string            `json:"type"`
+	RealmID   string            `json:"realmId"`
+	ClientID  string            `json:"clientId"`
+	UserID    string            `json:"userId"`
+	SessionID string            `json:"sessionId"`
+	IPAddress string            `json:"ipAddress"`
+	Error     string            `json:"error"`
+	Details   map[string]string `json:"details"`
+}
+
+// AdminEvent represents a Keycloak admin event(https://www.keycloak.org/docs-api/latest/rest-api/index.html#AdminEventRepresentation).
+type AdminEvent struct {
+	ID             string      `json:"id,omitempty"`
+	Time           int64       `json:"time"`
+	RealmID        string      `json:"realmId"`
+	AuthDetails    AuthDetails `json:"authDetails"`
+	OperationType  string      `json:"operationType"`
+	ResourceType   string      `json:"resourceType"`
+	ResourcePath   string      `json:"resourcePath"`
+	Representation string      `json:"representation"`
+	Error          string      `json:"error"`
+}
+
+// AuthDetails describes who performed an admin operation.
+type AuthDetails struct {
+	RealmID   string `json:"realmId"`
+	ClientID  string `json:"clientId"`
+	UserID    string `json:"userId"`
+	IPAddress string `json:"ipAddress"`
+}
+
+// EventQuery filters login events. Zero values are not used as filter.
+type EventQuery struct {
+	Types     []string
+	UserID    string
+	ClientID  string
+	IPAddress string
+	DateFrom  time.Time
+	DateTo    time.Time
+	First     int
+	Max       int
+}
+
+// AdminEventQuery filters admin events. Zero values are not used as filter.
+type AdminEventQuery struct {
+	OperationTypes []string
+	ResourceTypes  []string
+	ResourcePath   string
+	AuthRealm      string
+	AuthClient     string
+	AuthUser       string
+	DateFrom       time.Time
+	DateTo         time.Time
+	First          int
+	Max            int
+}
+
+// GetEventsConfig returns the event configuration of the realm.
+func (a *AdminClient) GetEventsConfig(ctx context.Context, realm string) (*RealmEventsConfig, error) {
+	var config RealmEventsConfig
+	if err := a.doRequest(ctx, http.MethodGet, "/"+url.PathEscape(realm)+"/events/config", nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// UpdateEventsConfig updates the event configuration of the realm.
+func (a *AdminClient) UpdateEventsConfig(ctx context.Context, realm string, config RealmEventsConfig) error {
+	return a.doRequest(ctx, http.MethodPut, "/"+url.PathEscape(realm)+"/events/config", config, nil)
+}
+
+// EnableEvents enables storage of login events and of admin events including their representation in the realm.
+func (a *AdminClient) EnableEvents(ctx context.Context, realm string) error {
+	config, err := a.GetEventsConfig(ctx, realm)
+	if err != nil {
+		return err
+	}
+
+	enabled := true
+	config.EventsEnabled = &enabled
+	config.AdminEventsEnabled = &enabled
+	config.AdminEventsDetailsEnabled = &enabled
+
+	return a.UpdateEventsConfig(ctx, realm, *config)
+}
+
+// GetEvents returns the stored login events of the realm matching the query, most recent first.
+func (a *AdminClient) GetEvents(ctx context.Context, realm string, query EventQuery) ([]Event, error) {
+	params := url.Values{}
+	for _, t := range query.Types {
+		params.Add("type", t)
+	}
+	setParam(params, "user", query.UserID)
+	setParam(params, "client", query.ClientID)
+	setParam(params, "ipAddress", query.IPAddress)
+	setDateParam(params, "dateFrom", query.DateFrom)
+	setDateParam(params, "dateTo", query.DateTo)
+	setIntParam(params, "first", query.First)
+	setIntParam(params, "max", query.Max)
+
+	var events []Event
+	if err := a.doRequest(ctx, http.MethodGet, "/"+url.PathEscape(realm)+"/events"+encodeQuery(params), nil, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetAdminEvents returns the stored admin events of the realm matching the query, most recent first.
+func (a *AdminClient) GetAdminEvents(ctx context.Context, realm string, query AdminEventQuery) ([]AdminEvent, error) {
+	params := url.Values{}
+	for _, t := range query.OperationTypes {
+		params.Add("operationTypes", t)
+	}
+	for _, t := range query.ResourceTypes {
+		params.Add("resourceTypes", t)
+	}
+	setParam(params, "resourcePath", query.ResourcePath)
+	setParam(params, "authRealm", query.AuthRealm)
+	setParam(params, "authClient", query.AuthClient)
+	setParam(params, "authUser", query.AuthUser)
+	setDateParam(params, "dateFrom", query.DateFrom)
+	setDateParam(params, "dateTo", query.DateTo)
+	setIntParam(params, "first", query.First)
+	setIntParam(params, "max", query.Max)
+
+	var events []AdminEvent
+	if err := a.doRequest(ctx, http.MethodGet, "/"+url.PathEscape(realm)+"/admin-events"+encodeQuery(params), nil, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ClearEvents deletes all stored login events of the realm.
+func (a *AdminClient) ClearEvents(ctx context.Context, realm string) error {
+	return a.doRequest(ctx, http.MethodDelete, "/"+url.PathEscape(realm)+"/events", nil, nil)
+}
+
+// ClearAdminEvents deletes all stored admin events of the realm.
+func (a *AdminClient) ClearAdminEvents(ctx context.Context, realm string) error {
+	return a.doRequest(ctx, http.MethodDelete, "/"+url.PathEscape(realm)+"/admin-events", nil, nil)
+}
+
+// SubscribeEvents polls the login events of the realm and delivers the ones stored after the subscription,
+// oldest first, on the returned channel. Every event is delivered once, also when more events than
+// fit into a page are stored between two polls. The channel is closed when ctx is done, and when polling fails
+// persistently, e.g. because the realm was deleted or the credentials were rejected, so a closed channel
+// while ctx is not done means the subscription has ended with an error.
+// Event storage has to be enabled on the realm, see EnableEvents.
+func (a *AdminClient) SubscribeEvents(ctx context.Context, realm string) (<-chan Event, error) {
+	events, err := a.GetEvents(ctx, realm, EventQuery{Max: eventsPollMax})
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Event)
+	seen := newEventTracker(events)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(eventsPollInterval)
+		defer ticker.Stop()
+
+		failures := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			events, err := a.eventsSince(ctx, realm, seen.last)
+			if err != nil {
+				// errors of the request, like a missing realm, do not go away by retrying
+				var apiErr *APIError
+				if failures++; failures >= eventsPollRetries ||
+					(errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500) {
+					return
+				}
+				continue
+			}
+			failures = 0
+
+			for _, e := range seen.next(events) {
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// eventsSince returns the login events of the realm stored at or after the time in milliseconds, most recent first.
+// The events are paged until a page contains an older event or is not full.
+func (a *AdminClient) eventsSince(ctx context.Context, realm string, since int64) ([]Event, error) {
+	query := EventQuery{Max: eventsPollMax}
+	if since > 0 {
+		// dateFrom is a day in the time zone of the server, so the day before covers every time zone
+		query.DateFrom = time.UnixMilli(since).UTC().AddDate(0, 0, -1)
+	}
+
+	var events []Event
+	for {
+		page, err := a.GetEvents(ctx, realm, query)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page...)
+		if len(page) < query.Max || page[len(page)-1].Time < since {
+			return events, nil
+		}
+		query.First += query.Max
+	}
+}
+
+// eventTracker remembers the events already delivered by SubscribeEvents.
+// Events carry no id before Keycloak 22, so they are identified by their content.
+type eventTracker struct {
+	last int64
+	seen map[string]bool
+}
+
+func newEventTracker(events []Event) *eventTracker {
+	t := &eventTracker{seen: make(map[string]bool)}
+	t.next(events)
+	return t
+}
+
+// next returns the events not seen before, oldest first.
+func (t *eventTracker) next(events []Event) []Event {
+	var fresh []Event
+	for _, e := range events {
+		key := eventKey(e)
+		if e.Time < t.last || t.seen[key] {
+			continue
+		}
+		t.seen[key] = true
+		fresh = append(fresh, e)
+	}
+
+	sort.SliceStable(fresh, func(i, j int) bool { return fresh[i].Time < fresh[j].Time })
+
+	if len(fresh) > 0 {
+		t.last = fresh[len(fresh)-1].Time
+		// events older than the latest one cannot be returned anymore
+		for key := range t.seen {
+			if !strings.HasPrefix(key, strconv.FormatInt(t.last, 10)+"|") {
+				delete(t.seen, key)
+			}
+		}
+		for _, e := range fresh {
+			if e.Time == t.last {
+				t.seen[eventKey(e)] = true
+			}
+		}
+	}
+
+	return fresh
+}
+
+func eventKey(e Event) string {
+	return fmt.Sprintf("%d|%s|%s|%s|%s|%s|%s|%v", e.Time, e.ID, e.Type, e.ClientID, e.UserID, e.SessionID, e.Error, e.Details)
+}
+
+func setParam(params url.Values, key, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
+
+func setIntParam(params url.Values, key string, value int) {
+	if value != 0 {
+		params.Set(key, strconv.Itoa(value))
+	}
+}
+
+func setDateParam(params url.Values, key string, value time.Time) {
+	if !value.IsZero() {
+		params.Set(key, value.Format(eventsDateLayout))
+	}
+}
+
+func encodeQuery(params url.Values) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + params.Encode()
+}
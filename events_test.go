@@ -0,0 +1,182 @@
+package keycloak
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestEventTracker(t *testing.T) {
+	tracker := newEventTracker([]Event{
+		{Time: 2, Type: "LOGIN"},
+		{Time: 1, Type: "LOGIN"},
+	})
+
+	fresh := tracker.next([]Event{
+		{Time: 3, Type: "LOGOUT"},
+		{Time: 2, Type: "LOGIN_ERROR"},
+		{Time: 2, Type: "LOGIN"},
+		{Time: 1, Type: "LOGIN"},
+	})
+	if len(fresh) != 2 || fresh[0].Type != "LOGIN_ERROR" || fresh[1].Type != "LOGOUT" {
+		t.Errorf("next() = %v", fresh)
+		return
+	}
+
+	if fresh = tracker.next([]Event{{Time: 3, Type: "LOGOUT"}, {Time: 2, Type: "LOGIN_ERROR"}}); len(fresh) != 0 {
+		t.Errorf("next() = %v", fresh)
+	}
+}
+
+func TestAdminClient_SubscribeEventsPaging(t *testing.T) {
+	// 250 stored events, most recent first, as Keycloak returns them
+	var stored []Event
+	for i := 250; i > 0; i-- {
+		stored = append(stored, Event{Time: int64(i), Type: "LOGIN"})
+	}
+	var requests int
+	var deleted atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/realms/master/protocol/openid-connect/token":
+			writeJSON(w, http.StatusOK, Token{AccessToken: "token"})
+		case "/admin/realms/" + realm + "/events":
+			if deleted.Load() {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "Realm not found."})
+				return
+			}
+			requests++
+			first, _ := strconv.Atoi(r.URL.Query().Get("first"))
+			max, _ := strconv.Atoi(r.URL.Query().Get("max"))
+			writeJSON(w, http.StatusOK, stored[min(first, len(stored)):min(first+max, len(stored))])
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Realm not found."})
+		}
+	}))
+	defer server.Close()
+
+	adminClient := &AdminClient{ServerURL: server.URL, Realm: masterRealm, client: server.Client()}
+	ctx := context.Background()
+
+	events, err := adminClient.eventsSince(ctx, realm, 0)
+	if err != nil || len(events) != len(stored) || requests != 3 {
+		t.Errorf("eventsSince() = %d events in %d requests, error = %v", len(events), requests, err)
+		return
+	}
+
+	requests = 0
+	if events, err = adminClient.eventsSince(ctx, realm, 200); err != nil || len(events) != eventsPollMax || requests != 1 {
+		t.Errorf("eventsSince() = %d events in %d requests, error = %v", len(events), requests, err)
+		return
+	}
+
+	// a subscription to a missing realm ends instead of polling forever
+	subscription, err := adminClient.SubscribeEvents(ctx, realm)
+	if err != nil {
+		t.Errorf("SubscribeEvents() error = %v", err)
+		return
+	}
+	deleted.Store(true)
+	select {
+	case _, ok := <-subscription:
+		if ok {
+			t.Errorf("SubscribeEvents() delivered an event of a missing realm")
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("SubscribeEvents() did not end for a missing realm")
+	}
+}
+
+func TestAdminClient_Events(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	if err = adminClient.EnableEvents(ctx, realm); err != nil {
+		t.Errorf("EnableEvents() error = %v", err)
+		return
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	events, err := adminClient.SubscribeEvents(subCtx, realm)
+	if err != nil {
+		t.Errorf("SubscribeEvents() error = %v", err)
+		return
+	}
+
+	cfg, err := container.GetOIDCConfig(ctx, realm, client)
+	if err != nil {
+		t.Errorf("GetOIDCConfig() error = %v", err)
+		return
+	}
+
+	resp, err := http.PostForm(cfg.TokenURL, url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"username":      {"nobody"},
+		"password":      {"wrong"},
+	})
+	if err != nil {
+		t.Errorf("http.PostForm() error = %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	e, ok := <-events
+	if !ok || e.Type != "LOGIN_ERROR" || e.ClientID != client {
+		t.Errorf("SubscribeEvents() = %v, %v", e, ok)
+		return
+	}
+
+	stored, err := adminClient.GetEvents(ctx, realm, EventQuery{Types: []string{"LOGIN_ERROR"}, ClientID: client, DateFrom: time.Now().Add(-24 * time.Hour)})
+	if err != nil || len(stored) != 1 {
+		t.Errorf("GetEvents() = %v, error = %v", stored, err)
+		return
+	}
+
+	c, err := adminClient.GetClient(realm, client)
+	if err != nil {
+		t.Errorf("GetClient() error = %v", err)
+		return
+	}
+
+	if err = adminClient.UpdateClient(ctx, realm, *c); err != nil {
+		t.Errorf("UpdateClient() error = %v", err)
+		return
+	}
+
+	adminEvents, err := adminClient.GetAdminEvents(ctx, realm, AdminEventQuery{OperationTypes: []string{"UPDATE"}, ResourceTypes: []string{"CLIENT"}})
+	if err != nil || len(adminEvents) != 1 || adminEvents[0].Representation == "" {
+		t.Errorf("GetAdminEvents() = %v, error = %v", adminEvents, err)
+		return
+	}
+
+	if err = adminClient.ClearEvents(ctx, realm); err != nil {
+		t.Errorf("ClearEvents() error = %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package keycloak
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// requestFingerprint hashes everything in the request that determines the state of a started KeycloakContainer,
+// which WithReuse and WithWarmStart use to tell whether a container or an image can be shared.
+// Host callback ports change on every run, and log options like log levels do not affect the stored data,
+// so they are ignored.
+func requestFingerprint(req *testcontainers.GenericContainerRequest) (string, error) {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "image=%s\n", req.Image)
+	for _, arg := range req.Cmd {
+		if !isLogOption(strings.TrimPrefix(arg, "--")) {
+			fmt.Fprintf(h, "cmd=%s\n", arg)
+		}
+	}
+	for _, port := range req.ExposedPorts {
+		fmt.Fprintf(h, "port=%s\n", port)
+	}
+
+	keys := make([]string, 0, len(req.Env))
+	for key := range req.Env {
+		if key != keycloakHostCallbackPortsEnv && key != legacyLogLevelEnv && !strings.HasPrefix(key, "KC_LOG") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(h, "env=%s=%s\n", key, req.Env[key])
+	}
+
+	for i, f := range req.Files {
+		fmt.Fprintf(h, "file=%s mode=%o\n", f.ContainerFilePath, f.FileMode)
+		if f.Reader != nil {
+			data, err := io.ReadAll(f.Reader)
+			if err != nil {
+				return "", err
+			}
+			req.Files[i].Reader = bytes.NewReader(data)
+			if f.ContainerFilePath == keycloakConfigFile {
+				data = withoutLogOptions(data)
+			}
+			h.Write(data)
+			continue
+		}
+		if err := hashPath(h, f.HostFilePath); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashPath writes the contents of the file, or of all files below the directory, to h.
+func hashPath(h hash.Hash, path string) error {
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "path=%s\n", filepath.ToSlash(rel))
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(h, f)
+		return err
+	})
+}
+
+// isLogOption returns whether the option, like log-level=info, only configures logging.
+func isLogOption(option string) bool {
+	return option == "log" || strings.HasPrefix(option, "log-") || strings.HasPrefix(option, "log=")
+}
+
+// withoutLogOptions returns the keycloak.conf content without the lines of log options.
+func withoutLogOptions(content []byte) []byte {
+	var b bytes.Buffer
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if !isLogOption(strings.TrimSpace(line)) {
+			b.WriteString(line)
+		}
+	}
+	return b.Bytes()
+}
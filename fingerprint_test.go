@@ -0,0 +1,73 @@
+package keycloak
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	realmFile := filepath.Join(dir, "realm.json")
+	if err := os.WriteFile(realmFile, []byte(`{"realm": "Test"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint := func(opts ...testcontainers.CustomizeRequestOption) string {
+		req := testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image: "keycloak/keycloak:26.0",
+				Env:   map[string]string{},
+				Cmd:   []string{keycloakStartupCommand},
+			},
+		}
+		for _, opt := range opts {
+			if err := opt(&req); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// the fingerprint is taken of the rendered configuration
+		c, err := requestConfig(&req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = applyConfig(&req, c); err != nil {
+			t.Fatal(err)
+		}
+		f, err := requestFingerprint(&req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	base := fingerprint(WithRealmImportFile(realmFile))
+
+	if got := fingerprint(WithRealmImportFile(realmFile)); got != base {
+		t.Errorf("fingerprint of the same request changed: %s != %s", got, base)
+	}
+	if got := fingerprint(WithRealmImportFile(realmFile), WithHostCallbacks(12345)); got != base {
+		t.Errorf("fingerprint depends on host callback ports")
+	}
+	if got := fingerprint(WithRealmImportFile(realmFile), WithLogLevels(LogLevelDebug, nil)); got != base {
+		t.Errorf("fingerprint depends on log levels")
+	}
+	for _, format := range []ConfigFormat{ConfigFormatEnv, ConfigFormatFile} {
+		withLogLevels := fingerprint(WithRealmImportFile(realmFile), WithConfigFormat(format), WithLogLevels(LogLevelDebug, nil))
+		if withLogLevels != fingerprint(WithRealmImportFile(realmFile), WithConfigFormat(format)) {
+			t.Errorf("fingerprint depends on log levels in config format %s", format)
+		}
+	}
+	if got := fingerprint(WithRealmImportFile(realmFile), WithContextPath("/auth")); got == base {
+		t.Errorf("fingerprint does not depend on options")
+	}
+
+	if err := os.WriteFile(realmFile, []byte(`{"realm": "Other"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := fingerprint(WithRealmImportFile(realmFile)); got == base {
+		t.Errorf("fingerprint does not depend on file contents")
+	}
+}
@@ -2,11 +2,14 @@ module github.com/stillya/testcontainers-keycloak
 
 go 1.25.0
 
-require github.com/testcontainers/testcontainers-go v0.43.0
+require (
+	github.com/moby/moby/api v1.54.2
+	github.com/moby/moby/client v0.4.0
+	github.com/testcontainers/testcontainers-go v0.43.0
+	golang.org/x/oauth2 v0.36.0
+)
 
 require (
-	github.com/moby/moby/api v1.54.2 // indirect
-	github.com/moby/moby/client v0.4.0 // indirect
 	github.com/shirou/gopsutil/v4 v4.26.5 // indirect
 )
 
@@ -0,0 +1,171 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// IdentityProvider represents a Keycloak identity provider(https://www.keycloak.org/docs-api/latest/rest-api/index.html#IdentityProviderRepresentation).
+type IdentityProvider struct {
+	Alias                     *string            `json:"alias,omitempty"`
+	DisplayName               *string            `json:"displayName,omitempty"`
+	InternalID                *string            `json:"internalId,omitempty"`
+	ProviderID                *string            `json:"providerId,omitempty"`
+	Enabled                   *bool              `json:"enabled,omitempty"`
+	TrustEmail                *bool              `json:"trustEmail,omitempty"`
+	StoreToken                *bool              `json:"storeToken,omitempty"`
+	AddReadTokenRoleOnCreate  *bool              `json:"addReadTokenRoleOnCreate,omitempty"`
+	AuthenticateByDefault     *bool              `json:"authenticateByDefault,omitempty"`
+	LinkOnly                  *bool              `json:"linkOnly,omitempty"`
+	HideOnLogin               *bool              `json:"hideOnLogin,omitempty"`
+	FirstBrokerLoginFlowAlias *string            `json:"firstBrokerLoginFlowAlias,omitempty"`
+	PostBrokerLoginFlowAlias  *string            `json:"postBrokerLoginFlowAlias,omitempty"`
+	Config                    *map[string]string `json:"config,omitempty"`
+}
+
+// IdentityProviderMapper represents a Keycloak identity provider mapper(https://www.keycloak.org/docs-api/latest/rest-api/index.html#IdentityProviderMapperRepresentation).
+type IdentityProviderMapper struct {
+	ID                     *string            `json:"id,omitempty"`
+	Name                   *string            `json:"name,omitempty"`
+	IdentityProviderAlias  *string            `json:"identityProviderAlias,omitempty"`
+	IdentityProviderMapper *string            `json:"identityProviderMapper,omitempty"`
+	Config                 *map[string]string `json:"config,omitempty"`
+}
+
+// FederatedIdentity represents a link between a Keycloak user and an identity provider(https://www.keycloak.org/docs-api/latest/rest-api/index.html#FederatedIdentityRepresentation).
+type FederatedIdentity struct {
+	IdentityProvider *string `json:"identityProvider,omitempty"`
+	UserID           *string `json:"userId,omitempty"`
+	UserName         *string `json:"userName,omitempty"`
+}
+
+// CreateIdentityProvider creates an identity provider in the realm.
+func (a *AdminClient) CreateIdentityProvider(ctx context.Context, realm string, idp IdentityProvider) error {
+	return a.doRequest(ctx, http.MethodPost, identityProvidersPath(realm), idp, nil)
+}
+
+// GetIdentityProviders returns all identity providers of the realm.
+func (a *AdminClient) GetIdentityProviders(ctx context.Context, realm string) ([]IdentityProvider, error) {
+	var idps []IdentityProvider
+	if err := a.doRequest(ctx, http.MethodGet, identityProvidersPath(realm), nil, &idps); err != nil {
+		return nil, err
+	}
+	return idps, nil
+}
+
+// GetIdentityProvider returns the identity provider with the given alias.
+func (a *AdminClient) GetIdentityProvider(ctx context.Context, realm, alias string) (*IdentityProvider, error) {
+	var idp IdentityProvider
+	if err := a.doRequest(ctx, http.MethodGet, identityProviderPath(realm, alias), nil, &idp); err != nil {
+		return nil, err
+	}
+	return &idp, nil
+}
+
+// UpdateIdentityProvider updates the identity provider identified by its alias.
+func (a *AdminClient) UpdateIdentityProvider(ctx context.Context, realm string, idp IdentityProvider) error {
+	if idp.Alias == nil {
+		return errors.New("identity provider alias must be provided")
+	}
+	return a.doRequest(ctx, http.MethodPut, identityProviderPath(realm, *idp.Alias), idp, nil)
+}
+
+// DeleteIdentityProvider deletes the identity provider with the given alias.
+func (a *AdminClient) DeleteIdentityProvider(ctx context.Context, realm, alias string) error {
+	return a.doRequest(ctx, http.MethodDelete, identityProviderPath(realm, alias), nil, nil)
+}
+
+// ImportIdentityProviderConfig reads the metadata at fromURL, e.g. an OpenID Connect discovery document
+// or a SAML entity descriptor, and returns it as identity provider config.
+// providerID is the type of the identity provider, e.g. oidc or saml.
+func (a *AdminClient) ImportIdentityProviderConfig(ctx context.Context, realm, providerID, fromURL string) (map[string]string, error) {
+	var config map[string]string
+	err := a.doRequest(ctx, http.MethodPost, "/"+url.PathEscape(realm)+"/identity-provider/import-config", map[string]string{
+		"providerId": providerID,
+		"fromUrl":    fromURL,
+	}, &config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// CreateIdentityProviderMapper creates a mapper for the identity provider with the given alias
+// and returns the id of the created mapper.
+func (a *AdminClient) CreateIdentityProviderMapper(ctx context.Context, realm, alias string, mapper IdentityProviderMapper) (string, error) {
+	if mapper.IdentityProviderAlias == nil {
+		mapper.IdentityProviderAlias = &alias
+	}
+	return a.createResource(ctx, identityProviderPath(realm, alias)+"/mappers", mapper)
+}
+
+// GetIdentityProviderMappers returns all mappers of the identity provider with the given alias.
+func (a *AdminClient) GetIdentityProviderMappers(ctx context.Context, realm, alias string) ([]IdentityProviderMapper, error) {
+	var mappers []IdentityProviderMapper
+	if err := a.doRequest(ctx, http.MethodGet, identityProviderPath(realm, alias)+"/mappers", nil, &mappers); err != nil {
+		return nil, err
+	}
+	return mappers, nil
+}
+
+// GetIdentityProviderMapper returns the mapper with the given id of the identity provider with the given alias.
+func (a *AdminClient) GetIdentityProviderMapper(ctx context.Context, realm, alias, id string) (*IdentityProviderMapper, error) {
+	var mapper IdentityProviderMapper
+	if err := a.doRequest(ctx, http.MethodGet, identityProviderMapperPath(realm, alias, id), nil, &mapper); err != nil {
+		return nil, err
+	}
+	return &mapper, nil
+}
+
+// UpdateIdentityProviderMapper updates the mapper identified by its id.
+func (a *AdminClient) UpdateIdentityProviderMapper(ctx context.Context, realm, alias string, mapper IdentityProviderMapper) error {
+	if mapper.ID == nil {
+		return errors.New("identity provider mapper id must be provided")
+	}
+	return a.doRequest(ctx, http.MethodPut, identityProviderMapperPath(realm, alias, *mapper.ID), mapper, nil)
+}
+
+// DeleteIdentityProviderMapper deletes the mapper with the given id.
+func (a *AdminClient) DeleteIdentityProviderMapper(ctx context.Context, realm, alias, id string) error {
+	return a.doRequest(ctx, http.MethodDelete, identityProviderMapperPath(realm, alias, id), nil, nil)
+}
+
+// GetFederatedIdentities returns the identity provider links of the user with the given id.
+func (a *AdminClient) GetFederatedIdentities(ctx context.Context, realm, userID string) ([]FederatedIdentity, error) {
+	var identities []FederatedIdentity
+	if err := a.doRequest(ctx, http.MethodGet, federatedIdentityPath(realm, userID), nil, &identities); err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// AddFederatedIdentity links the user with the given id to a user of the identity provider of the identity.
+func (a *AdminClient) AddFederatedIdentity(ctx context.Context, realm, userID string, identity FederatedIdentity) error {
+	if identity.IdentityProvider == nil {
+		return errors.New("identity provider of the federated identity must be provided")
+	}
+	return a.doRequest(ctx, http.MethodPost, federatedIdentityPath(realm, userID)+"/"+url.PathEscape(*identity.IdentityProvider), identity, nil)
+}
+
+// DeleteFederatedIdentity removes the link between the user with the given id and the identity provider with the given alias.
+func (a *AdminClient) DeleteFederatedIdentity(ctx context.Context, realm, userID, alias string) error {
+	return a.doRequest(ctx, http.MethodDelete, federatedIdentityPath(realm, userID)+"/"+url.PathEscape(alias), nil, nil)
+}
+
+func identityProvidersPath(realm string) string {
+	return "/" + url.PathEscape(realm) + "/identity-provider/instances"
+}
+
+func identityProviderPath(realm, alias string) string {
+	return identityProvidersPath(realm) + "/" + url.PathEscape(alias)
+}
+
+func identityProviderMapperPath(realm, alias, id string) string {
+	return identityProviderPath(realm, alias) + "/mappers/" + url.PathEscape(id)
+}
+
+func federatedIdentityPath(realm, userID string) string {
+	return userPath(realm, userID) + "/federated-identity"
+}
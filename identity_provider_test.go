@@ -0,0 +1,166 @@
+package keycloak
+
+import (
+	"context"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestAdminClient_IdentityProviders(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := NewMockOIDCProvider(nil)
+	if err != nil {
+		t.Errorf("NewMockOIDCProvider() error = %v", err)
+		return
+	}
+	defer provider.Close()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+		WithHostCallbackURLs(provider.URL()),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	backChannelURL, err := container.GetHostCallbackURL(provider.URL())
+	if err != nil {
+		t.Errorf("GetHostCallbackURL() error = %v", err)
+		return
+	}
+
+	config, err := adminClient.ImportIdentityProviderConfig(ctx, realm, "oidc", backChannelURL+"/.well-known/openid-configuration")
+	if err != nil {
+		t.Errorf("ImportIdentityProviderConfig() error = %v", err)
+		return
+	}
+	if config["tokenUrl"] != provider.URL()+"/token" {
+		t.Errorf("ImportIdentityProviderConfig() = %v", config)
+		return
+	}
+
+	alias := "upstream"
+	providerID := "oidc"
+	config["clientId"] = MockOIDCProviderClientID
+	config["clientSecret"] = MockOIDCProviderClientSecret
+	err = adminClient.CreateIdentityProvider(ctx, realm, IdentityProvider{
+		Alias:      &alias,
+		ProviderID: &providerID,
+		Config:     &config,
+	})
+	if err != nil {
+		t.Errorf("CreateIdentityProvider() error = %v", err)
+		return
+	}
+
+	idp, err := adminClient.GetIdentityProvider(ctx, realm, alias)
+	if err != nil {
+		t.Errorf("GetIdentityProvider() error = %v", err)
+		return
+	}
+
+	displayName := "Upstream"
+	idp.DisplayName = &displayName
+	if err = adminClient.UpdateIdentityProvider(ctx, realm, *idp); err != nil {
+		t.Errorf("UpdateIdentityProvider() error = %v", err)
+		return
+	}
+
+	idps, err := adminClient.GetIdentityProviders(ctx, realm)
+	if err != nil || len(idps) != 1 || *idps[0].DisplayName != displayName {
+		t.Errorf("GetIdentityProviders() = %v, error = %v", idps, err)
+		return
+	}
+
+	name := "email"
+	mapperType := "oidc-user-attribute-idp-mapper"
+	id, err := adminClient.CreateIdentityProviderMapper(ctx, realm, alias, IdentityProviderMapper{
+		Name:                   &name,
+		IdentityProviderMapper: &mapperType,
+		Config: &map[string]string{
+			"syncMode":       "INHERIT",
+			"claim":          "email",
+			"user.attribute": "email",
+		},
+	})
+	if err != nil {
+		t.Errorf("CreateIdentityProviderMapper() error = %v", err)
+		return
+	}
+
+	mapper, err := adminClient.GetIdentityProviderMapper(ctx, realm, alias, id)
+	if err != nil || *mapper.Name != name {
+		t.Errorf("GetIdentityProviderMapper() = %v, error = %v", mapper, err)
+		return
+	}
+
+	(*mapper.Config)["claim"] = "mail"
+	if err = adminClient.UpdateIdentityProviderMapper(ctx, realm, alias, *mapper); err != nil {
+		t.Errorf("UpdateIdentityProviderMapper() error = %v", err)
+		return
+	}
+
+	if err = adminClient.DeleteIdentityProviderMapper(ctx, realm, alias, id); err != nil {
+		t.Errorf("DeleteIdentityProviderMapper() error = %v", err)
+		return
+	}
+
+	mappers, err := adminClient.GetIdentityProviderMappers(ctx, realm, alias)
+	if err != nil || len(mappers) != 0 {
+		t.Errorf("GetIdentityProviderMappers() = %v, error = %v", mappers, err)
+		return
+	}
+
+	userID, err := adminClient.createResource(ctx, "/"+realm+"/users", map[string]interface{}{
+		"username": "linked-user",
+		"enabled":  true,
+	})
+	if err != nil || userID == "" {
+		t.Errorf("create user = %v, error = %v", userID, err)
+		return
+	}
+
+	upstreamUserID := "upstream-user-id"
+	upstreamUserName := "upstream-user"
+	err = adminClient.AddFederatedIdentity(ctx, realm, userID, FederatedIdentity{
+		IdentityProvider: &alias,
+		UserID:           &upstreamUserID,
+		UserName:         &upstreamUserName,
+	})
+	if err != nil {
+		t.Errorf("AddFederatedIdentity() error = %v", err)
+		return
+	}
+
+	identities, err := adminClient.GetFederatedIdentities(ctx, realm, userID)
+	if err != nil || len(identities) != 1 || *identities[0].IdentityProvider != alias || *identities[0].UserID != upstreamUserID {
+		t.Errorf("GetFederatedIdentities() = %v, error = %v", identities, err)
+		return
+	}
+
+	if err = adminClient.DeleteFederatedIdentity(ctx, realm, userID, alias); err != nil {
+		t.Errorf("DeleteFederatedIdentity() error = %v", err)
+		return
+	}
+
+	if identities, err = adminClient.GetFederatedIdentities(ctx, realm, userID); err != nil || len(identities) != 0 {
+		t.Errorf("GetFederatedIdentities() after unlinking = %v, error = %v", identities, err)
+		return
+	}
+
+	if err = adminClient.DeleteIdentityProvider(ctx, realm, alias); err != nil {
+		t.Errorf("DeleteIdentityProvider() error = %v", err)
+	}
+}
@@ -2,10 +2,14 @@ package keycloak
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/log"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -22,6 +26,8 @@ const (
 	keycloakAdminBootstrapPasswordEnv = "KC_BOOTSTRAP_ADMIN_PASSWORD"
 	keycloakContextPathEnv            = "KEYCLOAK_CONTEXT_PATH"
 	keycloakTlsEnv                    = "KEYCLOAK_TLS"
+	keycloakNetworkAliasEnv           = "KEYCLOAK_NETWORK_ALIAS"
+	keycloakHostnameEnv               = "KEYCLOAK_HOSTNAME_URL"
 	keycloakStartupCommand            = "start-dev"
 	keycloakPort                      = "8080/tcp"
 	keycloakHttpsPort                 = "8443/tcp"
@@ -32,10 +38,19 @@ const (
 type KeycloakContainer struct {
 	testcontainers.Container
 
-	username    string
-	password    string
-	enableTLS   bool
-	contextPath string
+	username     string
+	password     string
+	enableTLS    bool
+	port         string
+	contextPath  string
+	networkAlias string
+	hostname     string
+	legacy       bool
+	config       *Config
+
+	hostCallbackPorts []int
+	mailbox           *Mailbox
+	logs              *LogCollector
 }
 
 // GetAdminClient returns an AdminClient for the KeycloakContainer.
@@ -53,22 +68,43 @@ func (k *KeycloakContainer) GetAuthServerURL(ctx context.Context) (string, error
 	if err != nil {
 		return "", err
 	}
+	port, err := k.MappedPort(ctx, k.port)
+	if err != nil {
+		return "", err
+	}
 	if k.enableTLS {
-		port, err := k.MappedPort(ctx, keycloakHttpsPort)
-		if err != nil {
-			return "", err
-		}
 		return fmt.Sprintf("https://%s:%s%s", host, port.Port(), k.contextPath), nil
 	} else {
-		port, err := k.MappedPort(ctx, keycloakPort)
-		if err != nil {
+		return fmt.Sprintf("http://%s:%s%s", host, port.Port(), k.contextPath), nil
+	}
+}
+
+// GetInternalAuthServerURL returns the URL of the KeycloakContainer as seen by
+// other containers attached to the network configured with WithNetwork.
+func (k *KeycloakContainer) GetInternalAuthServerURL(ctx context.Context) (string, error) {
+	if k.networkAlias == "" {
+		return "", errors.New("no network alias configured, use WithNetwork")
+	}
+	return internalAuthServerURL(k.networkAlias, k.enableTLS, k.port, k.contextPath), nil
+}
+
+// GetIssuerURL returns the URL tokens of the realm are issued for.
+// If a hostname is configured with WithHostname it is used regardless
+// of whether the token is obtained from the host or from the container network.
+func (k *KeycloakContainer) GetIssuerURL(ctx context.Context, realm string) (string, error) {
+	authServerURL := k.hostname
+	if authServerURL == "" {
+		var err error
+		if authServerURL, err = k.GetAuthServerURL(ctx); err != nil {
 			return "", err
 		}
-		return fmt.Sprintf("http://%s:%s%s", host, port.Port(), k.contextPath), nil
 	}
+	return newOIDCConfig(authServerURL, realm).Issuer, nil
 }
 
 // Run starts a new KeycloakContainer with the given options.
+// If the container was created but failed to start, it is returned together with the error,
+// so it can still be inspected and terminated, e.g. with testcontainers.CleanupContainer.
 func Run(ctx context.Context, img string, opts ...testcontainers.ContainerCustomizer) (*KeycloakContainer, error) {
 	req := testcontainers.ContainerRequest{
 		Image: img,
@@ -90,12 +126,110 @@ func Run(ctx context.Context, img string, opts ...testcontainers.ContainerCustom
 		Started:          true,
 	}
 
+	var mailbox *Mailbox
+	// the Mailbox is stopped with the container once it exists
+	var created bool
+	defer func() {
+		if !created && mailbox != nil {
+			_ = mailbox.Close()
+		}
+	}()
+
 	for _, opt := range opts {
 		if err := opt.Customize(&genericContainerReq); err != nil {
 			return nil, err
 		}
 	}
 
+	logger := genericContainerReq.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	providers, err := requestProviders(&genericContainerReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if genericContainerReq.Env[keycloakSMTPCaptureEnv] != "" {
+		if mailbox, err = startSMTPCapture(&genericContainerReq); err != nil {
+			return nil, err
+		}
+	}
+
+	username := genericContainerReq.Env[keycloakAdminUsernameEnv]
+	password := genericContainerReq.Env[keycloakAdminPasswordEnv]
+
+	config, err := requestConfig(&genericContainerReq)
+	if err != nil {
+		return nil, err
+	}
+
+	version, versionKnown := imageVersion(genericContainerReq.Image)
+	legacy, err := isLegacyImage(genericContainerReq.Image, version, versionKnown)
+	if err != nil {
+		return nil, err
+	}
+	if legacy {
+		if err := applyLegacy(&genericContainerReq, config); err != nil {
+			return nil, err
+		}
+	} else if err := applyVersion(&genericContainerReq, config, version, versionKnown); err != nil {
+		return nil, err
+	}
+
+	hostname, pinHostname := genericContainerReq.Env[keycloakHostnameEnv]
+	if pinHostname {
+		if hostname == "" {
+			alias := genericContainerReq.Env[keycloakNetworkAliasEnv]
+			if alias == "" {
+				return nil, errors.New("WithHostname requires a hostname or a network alias configured with WithNetwork")
+			}
+			hostname = internalAuthServerURL(alias,
+				genericContainerReq.Env[keycloakTlsEnv] != "",
+				serverPort(&genericContainerReq),
+				genericContainerReq.Env[keycloakContextPathEnv])
+		}
+		if legacy {
+			genericContainerReq.Env[legacyFrontendURLEnv] = hostname
+		} else if err := setHostname(config, hostname, version, versionKnown); err != nil {
+			return nil, err
+		}
+	}
+
+	if legacy {
+		// legacy images are configured by environment variables
+		config = nil
+	} else {
+		if configFile := genericContainerReq.Env[keycloakConfigFileEnv]; configFile != "" {
+			if err := logUnknownConfigFileOptions(configFile, logger); err != nil {
+				return nil, err
+			}
+		}
+		if err := applyConfig(&genericContainerReq, config); err != nil {
+			return nil, err
+		}
+		if !pinHostname {
+			hostname = configHostname(config)
+		}
+	}
+
+	if genericContainerReq.Reuse {
+		if err := prepareReuse(&genericContainerReq); err != nil {
+			return nil, err
+		}
+	}
+
+	var warmStartTag string
+	var warmStarted bool
+	if genericContainerReq.Env[keycloakWarmStartEnv] != "" {
+		var err error
+		if warmStartTag, warmStarted, err = prepareWarmStart(ctx, &genericContainerReq); err != nil {
+			return nil, err
+		}
+	}
+
+	port := serverPort(&genericContainerReq)
 	if genericContainerReq.WaitingFor == nil {
 		contextPath := genericContainerReq.Env[keycloakContextPathEnv]
 		if contextPath == "" {
@@ -103,28 +237,77 @@ func Run(ctx context.Context, img string, opts ...testcontainers.ContainerCustom
 		}
 		if genericContainerReq.Env[keycloakTlsEnv] != "" {
 			genericContainerReq.WaitingFor = wait.ForAll(wait.ForHTTP(contextPath).
-				WithPort(keycloakHttpsPort).
+				WithPort(port).
 				WithTLS(true).
 				WithAllowInsecure(true),
 				wait.ForLog("Running the server"))
 		} else {
-			genericContainerReq.WaitingFor = wait.ForAll(wait.ForHTTP(contextPath),
+			genericContainerReq.WaitingFor = wait.ForAll(wait.ForHTTP(contextPath).
+				WithPort(port),
 				wait.ForLog("Running the server"))
 		}
 	}
 
+	logs := NewLogCollector()
+	var logConsumerCfg testcontainers.LogConsumerConfig
+	if genericContainerReq.LogConsumerCfg != nil {
+		logConsumerCfg = *genericContainerReq.LogConsumerCfg
+	}
+	logConsumerCfg.Consumers = append(slices.Clone(logConsumerCfg.Consumers), logs)
+	genericContainerReq.LogConsumerCfg = &logConsumerCfg
+
 	container, err := testcontainers.GenericContainer(ctx, genericContainerReq)
+	if container == nil {
+		return nil, newStartupError(ctx, container, logs, err)
+	}
+	created = true
+
+	k := &KeycloakContainer{
+		Container:    container,
+		username:     username,
+		password:     password,
+		contextPath:  genericContainerReq.Env[keycloakContextPathEnv],
+		enableTLS:    genericContainerReq.Env[keycloakTlsEnv] != "",
+		port:         port,
+		networkAlias: genericContainerReq.Env[keycloakNetworkAliasEnv],
+		hostname:     hostname,
+		legacy:       legacy,
+		config:       config,
+
+		hostCallbackPorts: parseHostCallbackPorts(genericContainerReq.Env[keycloakHostCallbackPortsEnv]),
+		mailbox:           mailbox,
+		logs:              logs,
+	}
+	// the container is returned with the error, so it can be dumped and terminated by the caller
 	if err != nil {
-		return nil, err
+		return k, newStartupError(ctx, container, logs, err)
+	}
+
+	if genericContainerReq.Reuse {
+		if err = k.checkHealth(ctx); err != nil {
+			return k, fmt.Errorf("shared container %s is unhealthy: %w", genericContainerReq.Name, err)
+		}
+	}
+
+	if len(providers) > 0 {
+		if err = k.verifyProviders(ctx, providers, logger); err != nil {
+			return k, err
+		}
+	}
+
+	if warmStartTag != "" && !warmStarted {
+		if err = commitWarmStart(ctx, container, warmStartTag); err != nil {
+			return k, err
+		}
+	}
+
+	if mailbox != nil {
+		if err = k.configureSMTPCapture(ctx); err != nil {
+			return k, err
+		}
 	}
 
-	return &KeycloakContainer{
-		Container:   container,
-		username:    genericContainerReq.Env[keycloakAdminUsernameEnv],
-		password:    genericContainerReq.Env[keycloakAdminPasswordEnv],
-		contextPath: genericContainerReq.Env[keycloakContextPathEnv],
-		enableTLS:   genericContainerReq.Env[keycloakTlsEnv] != "",
-	}, nil
+	return k, nil
 }
 
 // WithRealmImportFile is option to import a realm file into KeycloakContainer.
@@ -137,9 +320,7 @@ func WithRealmImportFile(realmImportFile string) testcontainers.CustomizeRequest
 		}
 		req.Files = append(req.Files, realmFile)
 
-		processKeycloakArgs(req, []string{"--import-realm"})
-
-		return nil
+		return setOption(req, "import-realm", "")
 	}
 }
 
@@ -148,23 +329,27 @@ func WithRealmImportFile(realmImportFile string) testcontainers.CustomizeRequest
 // See https://www.keycloak.org/server/configuration-provider
 func WithProviders(providerFiles ...string) testcontainers.CustomizeRequestOption {
 	return func(req *testcontainers.GenericContainerRequest) error {
-		for _, providerFile := range providerFiles {
-			provider := testcontainers.ContainerFile{
-				HostFilePath:      providerFile,
-				ContainerFilePath: defaultProviders + filepath.Base(providerFile),
-				FileMode:          0o755,
-			}
-			req.Files = append(req.Files, provider)
+		for _, file := range providerFiles {
+			req.Files = append(req.Files, providerFile(file))
 		}
 
 		return nil
 	}
 }
 
+// providerFile returns the provider JAR mounted into the providers directory,
+// which applyLegacy relocates to the deployments directory of legacy images.
+func providerFile(file string) testcontainers.ContainerFile {
+	return testcontainers.ContainerFile{
+		HostFilePath:      file,
+		ContainerFilePath: defaultProviders + filepath.Base(file),
+		FileMode:          0o755,
+	}
+}
+
 // WithTLS is option to enable TLS for KeycloakContainer.
 func WithTLS(certFile, keyFile string) testcontainers.CustomizeRequestOption {
 	return func(req *testcontainers.GenericContainerRequest) error {
-		req.ExposedPorts = []string{keycloakHttpsPort}
 		cf := testcontainers.ContainerFile{
 			HostFilePath:      certFile,
 			ContainerFilePath: tlsFilePath + "/tls.crt",
@@ -179,12 +364,11 @@ func WithTLS(certFile, keyFile string) testcontainers.CustomizeRequestOption {
 		req.Files = append(req.Files, cf, kf)
 
 		req.Env[keycloakTlsEnv] = "true"
-		processKeycloakArgs(req,
-			[]string{"--https-certificate-file=" + tlsFilePath + "/tls.crt",
-				"--https-certificate-key-file=" + tlsFilePath + "/tls.key"},
-		)
-
-		return nil
+		req.ExposedPorts = []string{serverPort(req)}
+		if err := setOption(req, "https-certificate-file", tlsFilePath+"/tls.crt"); err != nil {
+			return err
+		}
+		return setOption(req, "https-certificate-key-file", tlsFilePath+"/tls.key")
 	}
 }
 
@@ -194,7 +378,7 @@ func WithAdminUsername(username string) testcontainers.CustomizeRequestOption {
 		if username == "" {
 			username = defaultKeycloakAdminUsername
 		}
-		req.Env[keycloakAdminUsernameEnv] = username 
+		req.Env[keycloakAdminUsernameEnv] = username
 		req.Env[keycloakAdminBootstrapUsernameEnv] = username
 
 		return nil
@@ -221,22 +405,65 @@ func WithContextPath(contextPath string) testcontainers.CustomizeRequestOption {
 			contextPath = defaultKeycloakContextPath
 		}
 		req.Env[keycloakContextPathEnv] = contextPath
-		processKeycloakArgs(req, []string{"--http-relative-path=" + contextPath})
+		return setOption(req, "http-relative-path", contextPath)
+	}
+}
+
+// WithNetwork is option to attach KeycloakContainer to the given network under the alias,
+// so other containers on that network can reach it via GetInternalAuthServerURL.
+func WithNetwork(networkName, alias string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if networkName == "" || alias == "" {
+			return errors.New("network name and alias must be provided")
+		}
+		req.Networks = append(req.Networks, networkName)
+		if req.NetworkAliases == nil {
+			req.NetworkAliases = make(map[string][]string)
+		}
+		req.NetworkAliases[networkName] = append(req.NetworkAliases[networkName], alias)
+		req.Env[keycloakNetworkAliasEnv] = alias
 
 		return nil
 	}
 }
 
-func processKeycloakArgs(req *testcontainers.GenericContainerRequest, args []string) {
-	if len(req.Cmd) == 0 {
-		req.Cmd = append([]string{keycloakStartupCommand}, args...)
-		return
+// WithHostname is option to pin the frontend URL of KeycloakContainer,
+// so the issuer of tokens is the same whether they are obtained from the host or from the container network.
+// The hostname should be a full URL, e.g. http://keycloak:8080/auth.
+// If it is empty, the URL returned by GetInternalAuthServerURL is used, which requires WithNetwork.
+// Back-channel requests keep using the URL they were sent to.
+// Images of Keycloak versions before 25 are configured with the hostname-url option of hostname:v1 instead.
+// See https://www.keycloak.org/server/hostname
+func WithHostname(hostname string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		req.Env[keycloakHostnameEnv] = hostname
+
+		return nil
 	}
+}
 
-	if req.Cmd[0] == keycloakStartupCommand {
-		req.Cmd = append(req.Cmd, args...)
-	} else if req.Cmd[0] != keycloakStartupCommand {
-		req.Cmd = append([]string{keycloakStartupCommand}, req.Cmd...)
-		req.Cmd = append(req.Cmd, args...)
+func internalAuthServerURL(alias string, enableTLS bool, port string, contextPath string) string {
+	if enableTLS {
+		return fmt.Sprintf("https://%s:%s%s", alias, containerPort(port), contextPath)
 	}
+	return fmt.Sprintf("http://%s:%s%s", alias, containerPort(port), contextPath)
+}
+
+// serverPort returns the container port Keycloak listens on, as configured by WithTLS or WithConfigFile.
+func serverPort(req *testcontainers.GenericContainerRequest) string {
+	if req.Env[keycloakTlsEnv] != "" {
+		if port := req.Env[keycloakHTTPSPortEnv]; port != "" {
+			return port
+		}
+		return keycloakHttpsPort
+	}
+	if port := req.Env[keycloakHTTPPortEnv]; port != "" {
+		return port
+	}
+	return keycloakPort
+}
+
+// containerPort strips the protocol from a port definition like 8080/tcp.
+func containerPort(port string) string {
+	return strings.SplitN(port, "/", 2)[0]
 }
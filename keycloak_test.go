@@ -3,11 +3,15 @@ package keycloak
 import (
 	"context"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
 )
 
 const (
@@ -297,6 +301,139 @@ func TestKeycloakContainer_GetAuthServerURL(t *testing.T) {
 	}
 }
 
+func TestKeycloakContainer_GetOIDCConfig(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithContextPath("/auth"),
+		WithRealmImportFile("testdata/realm-export.json"),
+		WithAdminUsername(username),
+		WithAdminPassword(password),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	cfg, err := container.GetOIDCConfig(ctx, realm, client)
+	if err != nil {
+		t.Errorf("GetOIDCConfig() error = %v", err)
+		return
+	}
+
+	if cfg.ClientSecret != "fuTlZ5kZr42JWxvMWwsdUSl1hUMumdrS" {
+		t.Errorf("GetOIDCConfig() secret = %v", cfg.ClientSecret)
+		return
+	}
+
+	resp, err := http.Get(cfg.Issuer + "/.well-known/openid-configuration")
+	if err != nil {
+		t.Errorf("http.Get() error = %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var discovery struct {
+		Issuer        string `json:"issuer"`
+		TokenEndpoint string `json:"token_endpoint"`
+		JWKSURI       string `json:"jwks_uri"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		t.Errorf("Decode() error = %v", err)
+		return
+	}
+
+	if discovery.Issuer != cfg.Issuer || discovery.TokenEndpoint != cfg.TokenURL || discovery.JWKSURI != cfg.JWKSURL {
+		t.Errorf("GetOIDCConfig() = %+v, discovery = %+v", cfg, discovery)
+		return
+	}
+
+	oauth2Config := cfg.OAuth2Config()
+	if oauth2Config.ClientID != client || oauth2Config.Endpoint.TokenURL != cfg.TokenURL {
+		t.Errorf("OAuth2Config() = %+v", oauth2Config)
+		return
+	}
+
+	if env := cfg.Env(); env["OIDC_ISSUER_URL"] != cfg.Issuer || env["OIDC_CLIENT_ID"] != client {
+		t.Errorf("Env() = %v", env)
+	}
+}
+
+func TestKeycloakContainer_WithNetwork(t *testing.T) {
+	ctx := context.Background()
+
+	nw, err := network.New(ctx)
+	if err != nil {
+		t.Errorf("network.New() error = %v", err)
+		return
+	}
+	testcontainers.CleanupNetwork(t, nw)
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithNetwork(nw.Name, "keycloak"),
+		WithHostname(""),
+		WithContextPath("/auth"),
+		WithAdminUsername(username),
+		WithAdminPassword(password),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	internalURL, err := container.GetInternalAuthServerURL(ctx)
+	if err != nil {
+		t.Errorf("GetInternalAuthServerURL() error = %v", err)
+		return
+	}
+	if internalURL != "http://keycloak:8080/auth" {
+		t.Errorf("GetInternalAuthServerURL() = %v", internalURL)
+		return
+	}
+
+	issuer, err := container.GetIssuerURL(ctx, "master")
+	if err != nil {
+		t.Errorf("GetIssuerURL() error = %v", err)
+		return
+	}
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	token, err := adminClient.getToken()
+	if err != nil {
+		t.Errorf("getToken() error = %v", err)
+		return
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.Split(token.AccessToken, ".")[1])
+	if err != nil {
+		t.Errorf("DecodeString() error = %v", err)
+		return
+	}
+
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		t.Errorf("Unmarshal() error = %v", err)
+		return
+	}
+
+	if claims.Issuer != issuer || issuer != internalURL+"/realms/master" {
+		t.Errorf("iss = %v, GetIssuerURL() = %v", claims.Issuer, issuer)
+	}
+}
+
 func WithCustomOption() testcontainers.CustomizeRequestOption {
 	return func(req *testcontainers.GenericContainerRequest) error {
 		req.Cmd = append(req.Cmd, "--health-enabled=false")
@@ -0,0 +1,117 @@
+package keycloak
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// The WildFly based distribution of Keycloak before version 17, e.g. jboss/keycloak:16.1.1,
+// is configured with environment variables of its entrypoint instead of command line options.
+// See https://github.com/keycloak/keycloak-containers/blob/16.1.1/server/README.md
+const (
+	legacyImageRepository  = "jboss/keycloak"
+	legacyContextPath      = "/auth"
+	legacyAdminUsernameEnv = "KEYCLOAK_USER"
+	legacyAdminPasswordEnv = "KEYCLOAK_PASSWORD"
+	legacyImportEnv        = "KEYCLOAK_IMPORT"
+	legacyFrontendURLEnv   = "KEYCLOAK_FRONTEND_URL"
+	legacyLogLevelEnv      = "KEYCLOAK_LOGLEVEL"
+	legacyRealmImport      = "/tmp/"
+	legacyProviders        = "/opt/jboss/keycloak/standalone/deployments/"
+	legacyTLSFilePath      = "/etc/x509/https"
+	legacyManagementPort   = "9990/tcp"
+)
+
+// isLegacyImage reports whether the image is a WildFly based Keycloak distribution: any jboss/keycloak image,
+// or a keycloak/keycloak image before version 17. Other images are never legacy, whatever their tag.
+// A jboss/keycloak image of a Quarkus version does not exist and is reported as error.
+func isLegacyImage(img string, v Version, known bool) (bool, error) {
+	repository, _ := splitImage(img)
+	if isRepository(repository, legacyImageRepository) {
+		if known && v.AtLeast(quarkusVersion) {
+			return false, fmt.Errorf("%s is not a keycloak image, %s images end with version 16", img, legacyImageRepository)
+		}
+		return true, nil
+	}
+	return isRepository(repository, keycloakImageRepository) && known && !v.AtLeast(quarkusVersion), nil
+}
+
+// applyLegacy translates the request and the Config built by the options for the Quarkus distribution
+// to the WildFly based distribution and fails for options it does not support.
+func applyLegacy(req *testcontainers.GenericContainerRequest, c *Config) error {
+	req.Env[legacyAdminUsernameEnv] = req.Env[keycloakAdminUsernameEnv]
+	req.Env[legacyAdminPasswordEnv] = req.Env[keycloakAdminPasswordEnv]
+	delete(req.Env, keycloakAdminUsernameEnv)
+	delete(req.Env, keycloakAdminPasswordEnv)
+	delete(req.Env, keycloakAdminBootstrapUsernameEnv)
+	delete(req.Env, keycloakAdminBootstrapPasswordEnv)
+
+	if req.Env[keycloakConfigFileEnv] != "" {
+		return fmt.Errorf("legacy keycloak images do not support keycloak.conf")
+	}
+
+	switch req.Env[keycloakContextPathEnv] {
+	case "":
+		req.Env[keycloakContextPathEnv] = legacyContextPath
+	case legacyContextPath:
+	default:
+		return fmt.Errorf("legacy keycloak images only support the %s context path", legacyContextPath)
+	}
+
+	// Docker Hub is the only registry of the jboss/keycloak images
+	if strings.HasPrefix(req.Image, legacyImageRepository+":") {
+		req.ImageSubstitutors = nil
+	}
+
+	var imports []string
+	for i, f := range req.Files {
+		dir, name := path.Split(f.ContainerFilePath)
+		switch {
+		case dir == defaultRealmImport:
+			req.Files[i].ContainerFilePath = legacyRealmImport + name
+			imports = append(imports, req.Files[i].ContainerFilePath)
+		case dir == defaultProviders:
+			req.Files[i].ContainerFilePath = legacyProviders + name
+		case dir == tlsFilePath+"/":
+			req.Files[i].ContainerFilePath = legacyTLSFilePath + "/" + name
+		}
+	}
+
+	for _, name := range c.Names() {
+		value, _ := c.Get(name)
+		switch name {
+		case "http-relative-path", "https-certificate-file", "https-certificate-key-file":
+			// covered by the context path check and the relocated files
+		case "import-realm":
+			req.Env[legacyImportEnv] = strings.Join(imports, ",")
+		case "hostname", "hostname-url":
+			req.Env[legacyFrontendURLEnv] = value
+		case "hostname-backchannel-dynamic":
+		case "log-level":
+			if strings.Contains(value, ":") {
+				return fmt.Errorf("legacy keycloak images do not support log levels per category")
+			}
+			req.Env[legacyLogLevelEnv] = strings.ToUpper(value)
+		default:
+			return fmt.Errorf("option --%s is not supported by legacy keycloak images", name)
+		}
+	}
+
+	// system properties are passed to standalone.sh, e.g. -Dkeycloak.profile.feature.upload_scripts=enabled
+	args := append([]string{"-bmanagement", "0.0.0.0"}, c.Args...)
+	req.Cmd = args
+
+	req.ExposedPorts = append(req.ExposedPorts, legacyManagementPort)
+	if req.WaitingFor == nil {
+		req.WaitingFor = wait.ForAll(
+			wait.ForHTTP("/health").WithPort(legacyManagementPort),
+			wait.ForLog("Admin console listening"),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestIsLegacyImage(t *testing.T) {
+	tests := []struct {
+		image   string
+		want    bool
+		wantErr bool
+	}{
+		{image: "jboss/keycloak:16.1.1", want: true},
+		{image: "jboss/keycloak", want: true},
+		{image: "docker.io/jboss/keycloak:latest", want: true},
+		{image: "quay.io/keycloak/keycloak:16.1.1", want: true},
+		{image: "keycloak/keycloak:17.0", want: false},
+		{image: "keycloak/keycloak:26.0", want: false},
+		{image: "keycloak/keycloak:nightly", want: false},
+		{image: "myorg/keycloak:1.2.0", want: false},
+		{image: "jboss/keycloak:26.0", wantErr: true},
+	}
+	// the version does not make other images legacy, even if it is known
+	if legacy, err := isLegacyImage("myorg/keycloak:16.0", Version{Major: 16}, true); legacy || err != nil {
+		t.Errorf("isLegacyImage() of a custom image = %v, error = %v", legacy, err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			v, known := imageVersion(tt.image)
+			got, err := isLegacyImage(tt.image, v, known)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("isLegacyImage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("isLegacyImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyLegacy(t *testing.T) {
+	newRequest := func(opts ...testcontainers.CustomizeRequestOption) (*testcontainers.GenericContainerRequest, error) {
+		req := &testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image: "jboss/keycloak:16.1.1",
+				ImageSubstitutors: []testcontainers.ImageSubstitutor{
+					testcontainers.NewCustomHubSubstitutor("quay.io"),
+				},
+				Env: map[string]string{
+					keycloakAdminUsernameEnv:          defaultKeycloakAdminUsername,
+					keycloakAdminBootstrapUsernameEnv: defaultKeycloakAdminUsername,
+					keycloakAdminPasswordEnv:          defaultKeycloakAdminPassword,
+					keycloakAdminBootstrapPasswordEnv: defaultKeycloakAdminPassword,
+				},
+				ExposedPorts: []string{keycloakPort},
+				Cmd:          []string{keycloakStartupCommand},
+			},
+		}
+		for _, opt := range opts {
+			if err := opt(req); err != nil {
+				return nil, err
+			}
+		}
+		c, err := requestConfig(req)
+		if err != nil {
+			return nil, err
+		}
+		return req, applyLegacy(req, c)
+	}
+
+	req, err := newRequest(
+		WithRealmImportFile("testdata/realm-export.json"),
+		WithProviders("testdata/provider.jar"),
+		WithTLS("testdata/tls.crt", "testdata/tls.key"),
+		WithAdminUsername(username),
+		WithAdminPassword(password),
+		WithLogLevels(LogLevelDebug, nil),
+	)
+	if err != nil {
+		t.Errorf("applyLegacy() error = %v", err)
+		return
+	}
+
+	wantEnv := map[string]string{
+		legacyAdminUsernameEnv: username,
+		legacyAdminPasswordEnv: password,
+		keycloakContextPathEnv: legacyContextPath,
+		keycloakTlsEnv:         "true",
+		legacyImportEnv:        "/tmp/realm-export.json",
+		legacyLogLevelEnv:      "DEBUG",
+	}
+	if fmt.Sprint(req.Env) != fmt.Sprint(wantEnv) {
+		t.Errorf("Env = %v, want %v", req.Env, wantEnv)
+	}
+
+	var files []string
+	for _, f := range req.Files {
+		files = append(files, f.ContainerFilePath)
+	}
+	wantFiles := []string{
+		"/tmp/realm-export.json",
+		legacyProviders + "provider.jar",
+		"/etc/x509/https/tls.crt",
+		"/etc/x509/https/tls.key",
+	}
+	if fmt.Sprint(files) != fmt.Sprint(wantFiles) {
+		t.Errorf("Files = %v, want %v", files, wantFiles)
+	}
+
+	if fmt.Sprint(req.Cmd) != "[-bmanagement 0.0.0.0]" {
+		t.Errorf("Cmd = %v", req.Cmd)
+	}
+	if req.ImageSubstitutors != nil {
+		t.Errorf("ImageSubstitutors = %v, want none", req.ImageSubstitutors)
+	}
+
+	if _, err = newRequest(WithContextPath("/")); err == nil {
+		t.Errorf("applyLegacy() with context path / error = nil")
+	}
+	if _, err = newRequest(WithLogLevels("", map[string]LogLevel{"org.keycloak.events": LogLevelDebug})); err == nil {
+		t.Errorf("applyLegacy() with category log levels error = nil")
+	}
+}
+
+func TestKeycloakContainer_Legacy(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"jboss/keycloak:16.1.1",
+		WithRealmImportFile("testdata/realm-export.json"),
+		WithAdminUsername(username),
+		WithAdminPassword(password),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	c, err := adminClient.GetClient(realm, client)
+	if err != nil {
+		t.Errorf("GetClient() error = %v", err)
+		return
+	}
+	if *c.ClientID != client {
+		t.Errorf("GetClient() = %v, want %v", *c.ClientID, client)
+	}
+
+	version, err := container.Version(ctx)
+	if err != nil {
+		t.Errorf("Version() error = %v", err)
+		return
+	}
+	if version.Major != 16 {
+		t.Errorf("Version() = %v, want major 16", version)
+	}
+}
@@ -0,0 +1,299 @@
+package keycloak
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	backchannelLogoutURLAttribute = "backchannel.logout.url"
+	backchannelLogoutEvent        = "http://schemas.openid.net/event/backchannel-logout"
+)
+
+// LogoutEvent is a validated OIDC back-channel logout token received by a LogoutReceiver.
+type LogoutEvent struct {
+	Issuer    string
+	Subject   string
+	SessionID string
+	Audience  []string
+	Claims    map[string]interface{}
+	Token     string
+}
+
+// LogoutReceiver is an HTTP server running in the test process that receives OIDC back-channel
+// logout requests from Keycloak and validates their logout tokens against the JWKS of the realm.
+// See https://openid.net/specs/openid-connect-backchannel-1_0.html
+type LogoutReceiver struct {
+	listener net.Listener
+	server   *http.Server
+	client   *http.Client
+
+	mu       sync.Mutex
+	issuers  map[string]*logoutIssuer
+	events   []*LogoutEvent
+	consumed map[*LogoutEvent]bool
+	errs     []error
+	received chan struct{}
+}
+
+// logoutIssuer is a realm the clients registered with a LogoutReceiver belong to.
+type logoutIssuer struct {
+	jwksURL string
+	clients []string
+}
+
+// NewLogoutReceiver starts a LogoutReceiver listening on a random local port.
+// Its port must be exposed to KeycloakContainer, e.g. with WithHostCallbackURLs(receiver.URL()),
+// before it can be registered with KeycloakContainer.RegisterLogoutReceiver.
+func NewLogoutReceiver() (*LogoutReceiver, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &LogoutReceiver{
+		listener: l,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+		issuers:  make(map[string]*logoutIssuer),
+		consumed: make(map[*LogoutEvent]bool),
+		received: make(chan struct{}),
+	}
+	r.server = &http.Server{Handler: http.HandlerFunc(r.handleLogout)}
+
+	go func() {
+		_ = r.server.Serve(l)
+	}()
+
+	return r, nil
+}
+
+// URL returns the URL of the LogoutReceiver on the host.
+func (r *LogoutReceiver) URL() string {
+	return "http://" + r.listener.Addr().String()
+}
+
+// Close stops the LogoutReceiver.
+func (r *LogoutReceiver) Close() error {
+	return r.server.Close()
+}
+
+// Events returns all logout events received so far.
+func (r *LogoutReceiver) Events() []*LogoutEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*LogoutEvent(nil), r.events...)
+}
+
+// Errors returns the validation errors of rejected logout requests.
+func (r *LogoutReceiver) Errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]error(nil), r.errs...)
+}
+
+// WaitForLogout blocks until a logout token for the session with the given id is received, or ctx is done.
+// An empty sid matches any session. Every event is returned only once.
+func (r *LogoutReceiver) WaitForLogout(ctx context.Context, sid string) (*LogoutEvent, error) {
+	for {
+		r.mu.Lock()
+		received := r.received
+		for _, e := range r.events {
+			if !r.consumed[e] && (sid == "" || e.SessionID == sid) {
+				r.consumed[e] = true
+				r.mu.Unlock()
+				return e, nil
+			}
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-received:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no logout for session %q received: %w", sid, ctx.Err())
+		}
+	}
+}
+
+// RegisterLogoutReceiver sets the back-channel logout URL of the client with the given clientID
+// to the LogoutReceiver and makes the receiver validate logout tokens against the JWKS of the realm.
+// Clients of several realms can be registered with the same LogoutReceiver.
+func (k *KeycloakContainer) RegisterLogoutReceiver(ctx context.Context, realm, clientID string, r *LogoutReceiver) error {
+	callbackURL, err := k.GetHostCallbackURL(r.URL())
+	if err != nil {
+		return err
+	}
+
+	authServerURL, err := k.GetAuthServerURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	issuer, err := k.GetIssuerURL(ctx, realm)
+	if err != nil {
+		return err
+	}
+
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	c, err := adminClient.GetClient(realm, clientID)
+	if err != nil {
+		return err
+	}
+
+	attributes := map[string]string{}
+	if c.Attributes != nil {
+		attributes = *c.Attributes
+	}
+	attributes[backchannelLogoutURLAttribute] = callbackURL
+	c.Attributes = &attributes
+
+	if err = adminClient.UpdateClient(ctx, realm, *c); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.register(issuer, newOIDCConfig(authServerURL, realm).JWKSURL, clientID)
+
+	return nil
+}
+
+// register makes the LogoutReceiver accept logout tokens of the issuer for the client, verified with the JWKS.
+func (r *LogoutReceiver) register(issuer, jwksURL, clientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.issuers[issuer]
+	if !ok {
+		i = &logoutIssuer{}
+		r.issuers[issuer] = i
+	}
+	i.jwksURL = jwksURL
+	if !slices.Contains(i.clients, clientID) {
+		i.clients = append(i.clients, clientID)
+	}
+}
+
+func (r *LogoutReceiver) handleLogout(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	event, err := r.validate(req.Context(), req.PostFormValue("logout_token"))
+	if err != nil {
+		r.mu.Lock()
+		r.errs = append(r.errs, err)
+		r.mu.Unlock()
+
+		w.Header().Set("Cache-Control", "no-store")
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	close(r.received)
+	r.received = make(chan struct{})
+	r.mu.Unlock()
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *LogoutReceiver) validate(ctx context.Context, token string) (*LogoutEvent, error) {
+	if token == "" {
+		return nil, errors.New("missing logout_token")
+	}
+
+	issuer, err := jwtIssuer(token)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	registered := len(r.issuers) > 0
+	var jwksURL string
+	var clients []string
+	if i, ok := r.issuers[issuer]; ok {
+		jwksURL, clients = i.jwksURL, slices.Clone(i.clients)
+	}
+	r.mu.Unlock()
+
+	if !registered {
+		return nil, errors.New("logout receiver is not registered with a client")
+	}
+	if jwksURL == "" {
+		return nil, fmt.Errorf("unexpected logout token issuer %q", issuer)
+	}
+
+	keys, err := r.fetchJWKS(ctx, jwksURL)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := verifyJWT(token, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &LogoutEvent{Audience: audience(claims), Claims: claims, Token: token}
+	event.Issuer, _ = claims["iss"].(string)
+	event.Subject, _ = claims["sub"].(string)
+	event.SessionID, _ = claims["sid"].(string)
+
+	if !slices.ContainsFunc(event.Audience, func(aud string) bool { return slices.Contains(clients, aud) }) {
+		return nil, fmt.Errorf("unexpected logout token audience %v", event.Audience)
+	}
+	if event.Subject == "" && event.SessionID == "" {
+		return nil, errors.New("logout token contains neither sub nor sid")
+	}
+	if _, ok := claims["nonce"]; ok {
+		return nil, errors.New("logout token must not contain a nonce")
+	}
+	events, _ := claims["events"].(map[string]interface{})
+	if _, ok := events[backchannelLogoutEvent]; !ok {
+		return nil, errors.New("logout token does not contain the back-channel logout event")
+	}
+
+	return event, nil
+}
+
+func (r *LogoutReceiver) fetchJWKS(ctx context.Context, jwksURL string) (JSONWebKeySet, error) {
+	var keys JSONWebKeySet
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return keys, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return keys, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return keys, fmt.Errorf("GET %s: unexpected status %d", jwksURL, resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&keys)
+	return keys, err
+}
@@ -0,0 +1,228 @@
+package keycloak
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestLogoutReceiver_WaitForLogout(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Errorf("GenerateKey() error = %v", err)
+		return
+	}
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, JSONWebKeySet{Keys: []JSONWebKey{newJSONWebKey("kid", &key.PublicKey)}})
+	}))
+	defer jwks.Close()
+
+	receiver, err := NewLogoutReceiver()
+	if err != nil {
+		t.Errorf("NewLogoutReceiver() error = %v", err)
+		return
+	}
+	defer receiver.Close()
+
+	// clients of two realms share the receiver
+	receiver.register("http://localhost/realms/Test", jwks.URL, client)
+	receiver.register("http://localhost/realms/Other", jwks.URL, "other-app")
+
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		status int
+	}{
+		{
+			name: "Valid",
+			claims: map[string]interface{}{
+				"iss":    "http://localhost/realms/Test",
+				"aud":    client,
+				"sub":    "user",
+				"sid":    "session",
+				"events": map[string]interface{}{backchannelLogoutEvent: map[string]interface{}{}},
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "OtherRealm",
+			claims: map[string]interface{}{
+				"iss":    "http://localhost/realms/Other",
+				"aud":    "other-app",
+				"sid":    "other-session",
+				"events": map[string]interface{}{backchannelLogoutEvent: map[string]interface{}{}},
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "ClientOfOtherRealm",
+			claims: map[string]interface{}{
+				"iss":    "http://localhost/realms/Other",
+				"aud":    client,
+				"sid":    "session",
+				"events": map[string]interface{}{backchannelLogoutEvent: map[string]interface{}{}},
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "WrongIssuer",
+			claims: map[string]interface{}{
+				"iss":    "http://localhost/realms/Unknown",
+				"aud":    client,
+				"sid":    "session",
+				"events": map[string]interface{}{backchannelLogoutEvent: map[string]interface{}{}},
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "MissingEvent",
+			claims: map[string]interface{}{
+				"iss": "http://localhost/realms/Test",
+				"aud": []string{client},
+				"sid": "session",
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := signJWT("kid", key, tt.claims)
+			if err != nil {
+				t.Errorf("signJWT() error = %v", err)
+				return
+			}
+
+			resp, err := http.PostForm(receiver.URL(), url.Values{"logout_token": {token}})
+			if err != nil {
+				t.Errorf("http.PostForm() error = %v", err)
+				return
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("http.PostForm() status = %v, want %v", resp.StatusCode, tt.status)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	event, err := receiver.WaitForLogout(ctx, "session")
+	if err != nil || event.Subject != "user" {
+		t.Errorf("WaitForLogout() = %v, error = %v", event, err)
+		return
+	}
+
+	if event, err = receiver.WaitForLogout(ctx, "other-session"); err != nil || event.Issuer != "http://localhost/realms/Other" {
+		t.Errorf("WaitForLogout() = %v, error = %v", event, err)
+		return
+	}
+
+	if errs := receiver.Errors(); len(errs) != 3 {
+		t.Errorf("Errors() = %v", errs)
+	}
+}
+
+func TestKeycloakContainer_RegisterLogoutReceiver(t *testing.T) {
+	ctx := context.Background()
+
+	receiver, err := NewLogoutReceiver()
+	if err != nil {
+		t.Errorf("NewLogoutReceiver() error = %v", err)
+		return
+	}
+	defer receiver.Close()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+		WithHostCallbackURLs(receiver.URL()),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	if err = container.RegisterLogoutReceiver(ctx, realm, client, receiver); err != nil {
+		t.Errorf("RegisterLogoutReceiver() error = %v", err)
+		return
+	}
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	err = adminClient.doRequest(ctx, http.MethodPost, "/"+realm+"/users", map[string]interface{}{
+		"username":      "logout-user",
+		"email":         "logout-user@example.com",
+		"firstName":     "Logout",
+		"lastName":      "User",
+		"enabled":       true,
+		"emailVerified": true,
+		"credentials": []map[string]interface{}{
+			{"type": "password", "value": "secret", "temporary": false},
+		},
+	}, nil)
+	if err != nil {
+		t.Errorf("create user error = %v", err)
+		return
+	}
+
+	cfg, err := container.GetOIDCConfig(ctx, realm, client)
+	if err != nil {
+		t.Errorf("GetOIDCConfig() error = %v", err)
+		return
+	}
+
+	resp, err := http.PostForm(cfg.TokenURL, url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"username":      {"logout-user"},
+		"password":      {"secret"},
+		"scope":         {"openid"},
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("password grant error = %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	sessions, err := adminClient.GetClientSessions(ctx, realm, client)
+	if err != nil || len(sessions) != 1 {
+		t.Errorf("GetClientSessions() = %v, error = %v", sessions, err)
+		return
+	}
+
+	if err = adminClient.LogoutUser(ctx, realm, sessions[0].UserID); err != nil {
+		t.Errorf("LogoutUser() error = %v", err)
+		return
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	event, err := receiver.WaitForLogout(waitCtx, sessions[0].ID)
+	if err != nil {
+		t.Errorf("WaitForLogout() error = %v, rejected = %v", err, receiver.Errors())
+		return
+	}
+
+	if event.Subject != sessions[0].UserID {
+		t.Errorf("WaitForLogout() = %v", event)
+	}
+}
@@ -0,0 +1,277 @@
+package keycloak
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// LogLevel is the level of a Keycloak log entry.
+// See https://www.keycloak.org/server/logging
+type LogLevel string
+
+const (
+	LogLevelFatal LogLevel = "FATAL"
+	LogLevelError LogLevel = "ERROR"
+	LogLevelWarn  LogLevel = "WARN"
+	LogLevelInfo  LogLevel = "INFO"
+	LogLevelDebug LogLevel = "DEBUG"
+	LogLevelTrace LogLevel = "TRACE"
+
+	logTimeLayout = "2006-01-02 15:04:05,000"
+)
+
+var logLevelSeverity = map[LogLevel]int{
+	LogLevelTrace: 0,
+	LogLevelDebug: 1,
+	LogLevelInfo:  2,
+	LogLevelWarn:  3,
+	LogLevelError: 4,
+	LogLevelFatal: 5,
+}
+
+// logLinePattern matches the default console log format of Keycloak:
+// %d{yyyy-MM-dd HH:mm:ss,SSS} %-5p [%c] (%t) %s%e%n
+var logLinePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2},\d{3})\s+(FATAL|ERROR|WARN|INFO|DEBUG|TRACE)\s+\[([^\]]*)\]\s+\(([^)]*)\)\s?(.*)$`)
+
+// javaExceptionPattern matches the exception heading a stack trace, e.g. java.lang.IllegalStateException: message
+var javaExceptionPattern = regexp.MustCompile(`^[a-zA-Z_$][\w$]*(\.[a-zA-Z_$][\w$]*)+(: |$)`)
+
+// LogEntry is a parsed line of the Keycloak log, including the stack trace following it.
+// Lines not written by the Keycloak logger, e.g. errors of the kc.sh command line, have no Time,
+// Category and Thread, and only lines prefixed with ERROR: have a Level.
+type LogEntry struct {
+	Time       time.Time
+	Level      LogLevel
+	Category   string
+	Thread     string
+	Message    string
+	StackTrace []string
+}
+
+// String returns the entry in a format similar to the Keycloak log.
+func (e LogEntry) String() string {
+	var b strings.Builder
+	if e.Level != "" {
+		b.WriteString(string(e.Level) + " ")
+	}
+	if e.Category != "" {
+		b.WriteString("[" + e.Category + "] ")
+	}
+	b.WriteString(e.Message)
+	for _, line := range e.StackTrace {
+		b.WriteString("\n" + line)
+	}
+	return b.String()
+}
+
+// IsError reports whether the entry is logged at ERROR or FATAL level.
+func (e LogEntry) IsError() bool {
+	return e.Level == LogLevelError || e.Level == LogLevelFatal
+}
+
+// Enables reports whether entries of the given level are logged when l is the minimum level.
+// Entries without a level are treated as INFO.
+func (l LogLevel) Enables(level LogLevel) bool {
+	if level == "" {
+		level = LogLevelInfo
+	}
+	return logLevelSeverity[level] >= logLevelSeverity[l]
+}
+
+// WithLogLevels is option to set the root log level of KeycloakContainer and the levels of log categories,
+// e.g. map[string]LogLevel{"org.keycloak.events": LogLevelDebug}. An empty root level keeps the default INFO.
+// See https://www.keycloak.org/server/logging#_configuring_the_log_level
+func WithLogLevels(root LogLevel, categories map[string]LogLevel) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		var levels []string
+		if root != "" {
+			if err := validateLogLevel(root); err != nil {
+				return err
+			}
+			levels = append(levels, strings.ToLower(string(root)))
+		}
+
+		for _, category := range slices.Sorted(maps.Keys(categories)) {
+			if category == "" {
+				return errors.New("log category must not be empty")
+			}
+			if err := validateLogLevel(categories[category]); err != nil {
+				return err
+			}
+			levels = append(levels, category+":"+strings.ToLower(string(categories[category])))
+		}
+
+		if len(levels) > 0 {
+			return setOption(req, "log-level", strings.Join(levels, ","))
+		}
+
+		return nil
+	}
+}
+
+func validateLogLevel(level LogLevel) error {
+	if _, ok := logLevelSeverity[level]; !ok {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+	return nil
+}
+
+// LogCollector is a testcontainers.LogConsumer that parses the Keycloak log into entries.
+// Every KeycloakContainer has one, see KeycloakContainer.LogEntries.
+type LogCollector struct {
+	mu      sync.Mutex
+	partial string
+	entries []LogEntry
+}
+
+// NewLogCollector returns an empty LogCollector.
+func NewLogCollector() *LogCollector {
+	return &LogCollector{}
+}
+
+// Accept parses the log output of the container.
+func (c *LogCollector) Accept(l testcontainers.Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	content := c.partial + string(l.Content)
+	lines := strings.Split(content, "\n")
+	// the last element is either empty or a line without its line break yet
+	c.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		c.entries = appendLogLine(c.entries, line)
+	}
+}
+
+// Entries returns all entries parsed so far.
+func (c *LogCollector) Entries() []LogEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]LogEntry(nil), c.entries...)
+}
+
+// Errors returns the entries logged at ERROR or FATAL level.
+func (c *LogCollector) Errors() []LogEntry {
+	return errorEntries(c.Entries())
+}
+
+// ParseLogs parses the Keycloak log read from r.
+func ParseLogs(r io.Reader) ([]LogEntry, error) {
+	var entries []LogEntry
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		entries = appendLogLine(entries, s.Text())
+	}
+
+	return entries, s.Err()
+}
+
+// StartupError is returned by Run when KeycloakContainer fails to start.
+// It contains the ERROR and FATAL entries of the Keycloak log, e.g. the reason a realm import failed.
+type StartupError struct {
+	Err    error
+	Errors []LogEntry
+}
+
+func (e *StartupError) Error() string {
+	var b strings.Builder
+	b.WriteString("keycloak failed to start: " + e.Err.Error())
+	for _, entry := range e.Errors {
+		b.WriteString("\n" + string(entry.Level) + " " + entry.Message)
+	}
+	return b.String()
+}
+
+func (e *StartupError) Unwrap() error {
+	return e.Err
+}
+
+// LogEntries returns the entries of the Keycloak log parsed so far.
+func (k *KeycloakContainer) LogEntries() []LogEntry {
+	if k.logs == nil {
+		return nil
+	}
+	return k.logs.Entries()
+}
+
+// newStartupError wraps the error of a failed start with the errors logged by the container.
+// The full log is read from the container if it still exists, because log production may not
+// have been started before the wait strategy failed.
+func newStartupError(ctx context.Context, container testcontainers.Container, logs *LogCollector, err error) *StartupError {
+	entries := logs.Entries()
+	if container != nil {
+		if rc, logsErr := container.Logs(ctx); logsErr == nil {
+			if parsed, parseErr := ParseLogs(rc); parseErr == nil && len(parsed) > 0 {
+				entries = parsed
+			}
+			_ = rc.Close()
+		}
+	}
+
+	return &StartupError{Err: err, Errors: errorEntries(entries)}
+}
+
+func appendLogLine(entries []LogEntry, line string) []LogEntry {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return entries
+	}
+
+	if m := logLinePattern.FindStringSubmatch(line); m != nil {
+		t, _ := time.ParseInLocation(logTimeLayout, m[1], time.Local)
+		return append(entries, LogEntry{
+			Time:     t,
+			Level:    LogLevel(m[2]),
+			Category: m[3],
+			Thread:   m[4],
+			Message:  m[5],
+		})
+	}
+
+	if msg, ok := strings.CutPrefix(line, "ERROR: "); ok {
+		return append(entries, LogEntry{Level: LogLevelError, Message: msg})
+	}
+
+	if len(entries) > 0 && isStackTraceLine(line, entries[len(entries)-1]) {
+		last := &entries[len(entries)-1]
+		last.StackTrace = append(last.StackTrace, line)
+		return entries
+	}
+
+	return append(entries, LogEntry{Message: line})
+}
+
+// isStackTraceLine reports whether the line continues the stack trace of the previous entry.
+func isStackTraceLine(line string, previous LogEntry) bool {
+	if previous.Time.IsZero() {
+		return false
+	}
+	if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "Caused by: ") {
+		return true
+	}
+	return len(previous.StackTrace) == 0 && javaExceptionPattern.MatchString(line)
+}
+
+func errorEntries(entries []LogEntry) []LogEntry {
+	var errs []LogEntry
+	for _, e := range entries {
+		if e.IsError() {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,98 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const testLog = `Updating the configuration and installing your custom providers, if any. Please wait.
+2024-10-18 10:00:01,100 INFO  [org.keycloak.quarkus.runtime.hostname.DefaultHostnameProvider] (main) Hostname settings: Base URL: <unset>
+2024-10-18 10:00:02,200 WARN  [org.keycloak.quarkus.runtime.KeycloakMain] (main) Running the server in development mode.
+2024-10-18 10:00:03,300 ERROR [org.keycloak.quarkus.runtime.cli.ExecutionExceptionHandler] (main) Failed to start server
+java.lang.RuntimeException: Failed to import realm
+	at org.keycloak.exportimport.dir.DirImportProvider.importModel(DirImportProvider.java:110)
+	at org.keycloak.exportimport.ExportImportManager.runImport(ExportImportManager.java:80)
+Caused by: com.fasterxml.jackson.core.JsonParseException: Unexpected character ('}' (code 125))
+	... 2 more
+ERROR: Failed to start server in (development) mode
+ERROR: Unexpected character ('}' (code 125))
+For more details run the same command passing the '--verbose' option.
+`
+
+func TestLogCollector(t *testing.T) {
+	c := NewLogCollector()
+
+	// feed the log in chunks not aligned to lines
+	for i := 0; i < len(testLog); i += 37 {
+		c.Accept(testcontainers.Log{LogType: testcontainers.StdoutLog, Content: []byte(testLog[i:min(i+37, len(testLog))])})
+	}
+
+	parsed, err := ParseLogs(strings.NewReader(testLog))
+	if err != nil {
+		t.Errorf("ParseLogs() error = %v", err)
+		return
+	}
+
+	for _, entries := range [][]LogEntry{c.Entries(), parsed} {
+		if len(entries) != 7 {
+			t.Errorf("got %d entries, want 7: %v", len(entries), entries)
+			return
+		}
+
+		if entries[1].Level != LogLevelInfo || entries[1].Category != "org.keycloak.quarkus.runtime.hostname.DefaultHostnameProvider" ||
+			entries[1].Thread != "main" || entries[1].Time.Second() != 1 {
+			t.Errorf("entries[1] = %+v", entries[1])
+		}
+
+		if entries[3].Level != LogLevelError || len(entries[3].StackTrace) != 5 {
+			t.Errorf("entries[3] = %+v, want ERROR with 5 stack trace lines", entries[3])
+		}
+
+		if entries[0].Level != "" || entries[6].Level != "" {
+			t.Errorf("unstructured lines have a level: %v, %v", entries[0].Level, entries[6].Level)
+		}
+	}
+
+	errs := c.Errors()
+	if len(errs) != 3 {
+		t.Errorf("Errors() = %v, want 3 entries", errs)
+		return
+	}
+	if errs[2].Message != "Unexpected character ('}' (code 125))" {
+		t.Errorf("Errors()[2].Message = %q", errs[2].Message)
+	}
+}
+
+func TestRun_StartupError(t *testing.T) {
+	ctx := context.Background()
+
+	realmFile := filepath.Join(t.TempDir(), "broken-realm.json")
+	if err := os.WriteFile(realmFile, []byte(`{"realm": "Broken",}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile(realmFile),
+	)
+	testcontainers.CleanupContainer(t, container)
+	if container == nil {
+		t.Errorf("Run() returned no container with the startup error")
+	}
+
+	var startupErr *StartupError
+	if !errors.As(err, &startupErr) {
+		t.Errorf("Run() error = %v, want StartupError", err)
+		return
+	}
+
+	if len(startupErr.Errors) == 0 {
+		t.Errorf("StartupError.Errors is empty")
+	}
+}
@@ -0,0 +1,340 @@
+package keycloak
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// mavenScopes are the dependency scopes that are part of the runtime class path of a provider.
+var mavenScopes = []string{"", "compile", "runtime"}
+
+var mavenPropertyPattern = regexp.MustCompile(`\$\{([^}]+)}`)
+
+// mavenCoordinate identifies an artifact as groupId:artifactId[:packaging[:classifier]]:version.
+type mavenCoordinate struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	Packaging  string
+	Classifier string
+}
+
+func parseMavenCoordinate(coordinate string) (mavenCoordinate, error) {
+	parts := strings.Split(coordinate, ":")
+	var c mavenCoordinate
+	switch len(parts) {
+	case 3:
+		c = mavenCoordinate{GroupID: parts[0], ArtifactID: parts[1], Version: parts[2]}
+	case 4:
+		c = mavenCoordinate{GroupID: parts[0], ArtifactID: parts[1], Packaging: parts[2], Version: parts[3]}
+	case 5:
+		c = mavenCoordinate{GroupID: parts[0], ArtifactID: parts[1], Packaging: parts[2], Classifier: parts[3], Version: parts[4]}
+	default:
+		return c, fmt.Errorf("invalid maven coordinate %q, want groupId:artifactId[:packaging[:classifier]]:version", coordinate)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return c, fmt.Errorf("invalid maven coordinate %q, want groupId:artifactId[:packaging[:classifier]]:version", coordinate)
+		}
+	}
+	if c.Packaging == "" {
+		c.Packaging = "jar"
+	}
+	return c, nil
+}
+
+func (c mavenCoordinate) String() string {
+	return c.GroupID + ":" + c.ArtifactID + ":" + c.Version
+}
+
+// key identifies the artifact regardless of its version, the way dependencies are managed and excluded.
+func (c mavenCoordinate) key() string {
+	return c.GroupID + ":" + c.ArtifactID
+}
+
+// path returns the path of the artifact file with the extension in the local repository.
+func (c mavenCoordinate) path(repository, extension string) string {
+	name := c.ArtifactID + "-" + c.Version
+	if c.Classifier != "" {
+		name += "-" + c.Classifier
+	}
+	dir := filepath.Join(append([]string{repository}, strings.Split(c.GroupID, ".")...)...)
+	return filepath.Join(dir, c.ArtifactID, c.Version, name+"."+extension)
+}
+
+// mavenPOM is the part of a pom.xml needed to resolve the runtime dependencies of an artifact.
+// See https://maven.apache.org/pom.html
+type mavenPOM struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     *struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"parent"`
+	Properties           mavenProperties   `xml:"properties"`
+	ManagedDependencies  []mavenDependency `xml:"dependencyManagement>dependencies>dependency"`
+	Dependencies         []mavenDependency `xml:"dependencies>dependency"`
+	managedDependencyMap map[string]mavenDependency
+}
+
+type mavenDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Type       string `xml:"type"`
+	Classifier string `xml:"classifier"`
+	Scope      string `xml:"scope"`
+	Optional   string `xml:"optional"`
+	Exclusions []struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+	} `xml:"exclusions>exclusion"`
+}
+
+type mavenProperties map[string]string
+
+func (p *mavenProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = make(mavenProperties)
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err = d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			(*p)[t.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// mavenResolver resolves artifacts from a local Maven repository without network access.
+type mavenResolver struct {
+	repository string
+	poms       map[string]*mavenPOM
+}
+
+func newMavenResolver(repository string) *mavenResolver {
+	return &mavenResolver{repository: repository, poms: make(map[string]*mavenPOM)}
+}
+
+// defaultMavenRepository returns the local repository in the home directory of the user, ~/.m2/repository.
+func defaultMavenRepository() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".m2", "repository"), nil
+}
+
+// artifact returns the path of the artifact file of the coordinate, which must exist in the repository.
+func (r *mavenResolver) artifact(c mavenCoordinate) (string, error) {
+	path := c.path(r.repository, c.Packaging)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%s not found in local maven repository %s, build and install it first", c, r.repository)
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// dependencies returns the coordinates of the compile and runtime dependencies of the artifact,
+// transitively, nearest first, skipping optional dependencies and honoring exclusions.
+// Conflicting versions are resolved the way Maven does: the nearest declaration wins.
+func (r *mavenResolver) dependencies(root mavenCoordinate) ([]mavenCoordinate, error) {
+	type node struct {
+		coordinate mavenCoordinate
+		exclusions []string
+	}
+
+	rootPOM, err := r.pom(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []mavenCoordinate
+	seen := map[string]bool{root.key(): true}
+	queue := []node{{coordinate: root}}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		pom, err := r.pom(n.coordinate)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range pom.Dependencies {
+			d = pom.interpolateDependency(d)
+			key := d.GroupID + ":" + d.ArtifactID
+			if seen[key] || excluded(n.exclusions, d.GroupID, d.ArtifactID) {
+				continue
+			}
+			// the dependency management of the root artifact overrides the versions and scopes of transitive
+			// dependencies, the one of the declaring POM only fills in what the dependency leaves out
+			if managed, ok := rootPOM.managedDependencyMap[key]; ok && pom != rootPOM {
+				d = manageDependency(d, managed, true)
+			} else if managed, ok = pom.managedDependencyMap[key]; ok {
+				d = manageDependency(d, managed, false)
+			}
+			if !slices.Contains(mavenScopes, d.Scope) || d.Optional == "true" || (d.Type != "" && d.Type != "jar") {
+				continue
+			}
+			if d.Version == "" {
+				return nil, fmt.Errorf("no version for dependency %s of %s", key, n.coordinate)
+			}
+			seen[key] = true
+
+			c := mavenCoordinate{GroupID: d.GroupID, ArtifactID: d.ArtifactID, Version: d.Version, Packaging: "jar", Classifier: d.Classifier}
+			dependencies = append(dependencies, c)
+
+			exclusions := slices.Clone(n.exclusions)
+			for _, e := range d.Exclusions {
+				exclusions = append(exclusions, e.GroupID+":"+e.ArtifactID)
+			}
+			queue = append(queue, node{coordinate: c, exclusions: exclusions})
+		}
+	}
+
+	return dependencies, nil
+}
+
+// pom returns the effective POM of the artifact, with its parents and imported dependency management applied.
+func (r *mavenResolver) pom(c mavenCoordinate) (*mavenPOM, error) {
+	pomCoordinate := mavenCoordinate{GroupID: c.GroupID, ArtifactID: c.ArtifactID, Version: c.Version}
+	if pom, ok := r.poms[pomCoordinate.String()]; ok {
+		return pom, nil
+	}
+
+	path := pomCoordinate.path(r.repository, "pom")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("pom of %s not found in local maven repository %s", pomCoordinate, r.repository)
+		}
+		return nil, err
+	}
+	var pom mavenPOM
+	if err = xml.Unmarshal(data, &pom); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if pom.Properties == nil {
+		pom.Properties = make(mavenProperties)
+	}
+
+	pom.managedDependencyMap = make(map[string]mavenDependency)
+	if pom.Parent != nil {
+		parent, err := r.pom(mavenCoordinate{GroupID: pom.Parent.GroupID, ArtifactID: pom.Parent.ArtifactID, Version: pom.Parent.Version})
+		if err != nil {
+			return nil, err
+		}
+		if pom.GroupID == "" {
+			pom.GroupID = parent.GroupID
+		}
+		if pom.Version == "" {
+			pom.Version = parent.Version
+		}
+		for name, value := range parent.Properties {
+			if _, ok := pom.Properties[name]; !ok {
+				pom.Properties[name] = value
+			}
+		}
+		for key, d := range parent.managedDependencyMap {
+			pom.managedDependencyMap[key] = d
+		}
+		pom.Dependencies = append(append([]mavenDependency(nil), parent.Dependencies...), pom.Dependencies...)
+	}
+	pom.Properties["project.groupId"] = pom.GroupID
+	pom.Properties["project.artifactId"] = pom.ArtifactID
+	pom.Properties["project.version"] = pom.Version
+	if pom.Parent != nil {
+		pom.Properties["project.parent.version"] = pom.Parent.Version
+	}
+
+	for _, d := range pom.ManagedDependencies {
+		d = pom.interpolateDependency(d)
+		if d.Scope == "import" && d.Type == "pom" {
+			bom, err := r.pom(mavenCoordinate{GroupID: d.GroupID, ArtifactID: d.ArtifactID, Version: d.Version})
+			if err != nil {
+				return nil, err
+			}
+			for key, managed := range bom.managedDependencyMap {
+				if _, ok := pom.managedDependencyMap[key]; !ok {
+					pom.managedDependencyMap[key] = managed
+				}
+			}
+			continue
+		}
+		pom.managedDependencyMap[d.GroupID+":"+d.ArtifactID] = d
+	}
+
+	r.poms[pomCoordinate.String()] = &pom
+	return &pom, nil
+}
+
+func (p *mavenPOM) interpolateDependency(d mavenDependency) mavenDependency {
+	d.GroupID = p.interpolate(d.GroupID)
+	d.ArtifactID = p.interpolate(d.ArtifactID)
+	d.Version = p.interpolate(d.Version)
+	d.Scope = p.interpolate(d.Scope)
+	d.Classifier = p.interpolate(d.Classifier)
+	return d
+}
+
+// interpolate replaces ${property} expressions with the properties of the POM.
+// Unknown properties are kept as they are.
+func (p *mavenPOM) interpolate(s string) string {
+	// properties may refer to other properties
+	for i := 0; i < 10 && strings.Contains(s, "${"); i++ {
+		replaced := mavenPropertyPattern.ReplaceAllStringFunc(s, func(expression string) string {
+			if value, ok := p.Properties[expression[2:len(expression)-1]]; ok {
+				return value
+			}
+			return expression
+		})
+		if replaced == s {
+			break
+		}
+		s = replaced
+	}
+	return s
+}
+
+// manageDependency applies the version, scope, optional flag and exclusions of the dependency management to
+// the dependency. Unless override is set, only what the dependency does not declare itself is applied.
+func manageDependency(d, managed mavenDependency, override bool) mavenDependency {
+	if managed.Version != "" && (override || d.Version == "") {
+		d.Version = managed.Version
+	}
+	if managed.Scope != "" && (override || d.Scope == "") {
+		d.Scope = managed.Scope
+	}
+	if managed.Optional != "" && d.Optional == "" {
+		d.Optional = managed.Optional
+	}
+	d.Exclusions = append(slices.Clone(d.Exclusions), managed.Exclusions...)
+	return d
+}
+
+func excluded(exclusions []string, groupID, artifactID string) bool {
+	for _, e := range exclusions {
+		g, a, _ := strings.Cut(e, ":")
+		if (g == "*" || g == groupID) && (a == "*" || a == artifactID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,449 @@
+package keycloak
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/log"
+)
+
+const (
+	// keycloakServicePrefix is the prefix of the service files that register the factories of Keycloak SPIs,
+	// e.g. META-INF/services/org.keycloak.authentication.AuthenticatorFactory.
+	keycloakServicePrefix     = "META-INF/services/org.keycloak."
+	keycloakMavenProvidersEnv = "KEYCLOAK_MAVEN_PROVIDERS"
+)
+
+// providerSPIs maps the factory interfaces of common SPIs to the names of the SPIs in the server info,
+// so the id of a factory is looked up among the providers of its SPI only.
+// Factories of other SPIs are looked up among the providers of all SPIs.
+var providerSPIs = map[string]string{
+	"org.keycloak.authentication.AuthenticatorFactory":            "authenticator",
+	"org.keycloak.authentication.ClientAuthenticatorFactory":      "client-authenticator",
+	"org.keycloak.authentication.FormActionFactory":               "form-action",
+	"org.keycloak.authentication.FormAuthenticatorFactory":        "form-authenticator",
+	"org.keycloak.authentication.RequiredActionFactory":           "required-action",
+	"org.keycloak.broker.provider.IdentityProviderFactory":        "identity-provider",
+	"org.keycloak.broker.provider.IdentityProviderMapper":         "identity-provider-mapper",
+	"org.keycloak.broker.social.SocialIdentityProviderFactory":    "social",
+	"org.keycloak.credential.CredentialProviderFactory":           "credential",
+	"org.keycloak.events.EventListenerProviderFactory":            "eventsListener",
+	"org.keycloak.policy.PasswordPolicyProviderFactory":           "password-policy",
+	"org.keycloak.protocol.ProtocolMapper":                        "protocol-mapper",
+	"org.keycloak.services.resource.RealmResourceProviderFactory": "realm-restapi-extension",
+	"org.keycloak.storage.UserStorageProviderFactory":             "user-storage",
+	"org.keycloak.theme.ThemeProviderFactory":                     "theme",
+	"org.keycloak.validate.ValidatorFactory":                      "validator",
+}
+
+// MavenProvider is a factory of a Keycloak SPI found in a provider JAR resolved by WithMavenProviders.
+type MavenProvider struct {
+	// Artifact is the Maven coordinate of the provider JAR.
+	Artifact string
+	// Factory is the class name of the factory, e.g. com.example.CustomAuthenticatorFactory.
+	Factory string
+	// SPI is the class name of the factory interface, e.g. org.keycloak.authentication.AuthenticatorFactory.
+	SPI string
+	// ID is the provider id returned by the getId method of the factory or of a superclass in the same JAR,
+	// or empty if it is not a string constant. Such factories are not verified, which Run logs,
+	// and Run fails if none of the factories of a JAR can be verified.
+	ID string
+}
+
+// WithMavenProviders is option to add providers from the local Maven repository ~/.m2/repository
+// to KeycloakContainer, given by coordinates like com.example:custom-authenticator:1.0.0-SNAPSHOT,
+// so no network access is required. The JARs must have been built and installed, e.g. with mvn install.
+// After startup, Run verifies via the server info that the factories registered in the
+// META-INF/services files of the JARs are registered by Keycloak, and fails otherwise.
+// The JARs are mounted like the ones of WithProviders, so legacy images get them as deployments.
+// See https://www.keycloak.org/server/configuration-provider
+func WithMavenProviders(coordinates ...string) testcontainers.ContainerCustomizer {
+	return &mavenProvidersOption{coordinates: coordinates}
+}
+
+// WithMavenProvidersAndDependencies is like WithMavenProviders, but also adds the compile and runtime
+// dependencies the POMs of the providers declare, transitively. Keycloak itself should be a provided dependency.
+func WithMavenProvidersAndDependencies(coordinates ...string) testcontainers.ContainerCustomizer {
+	return &mavenProvidersOption{coordinates: coordinates, dependencies: true}
+}
+
+type mavenProvidersOption struct {
+	coordinates  []string
+	dependencies bool
+	// repository is the local Maven repository, ~/.m2/repository if empty
+	repository string
+
+	// the JARs are resolved once, since the option may be shared by the containers of a Pool
+	once      sync.Once
+	files     []string
+	providers []MavenProvider
+	err       error
+}
+
+func (o *mavenProvidersOption) Customize(req *testcontainers.GenericContainerRequest) error {
+	o.once.Do(func() {
+		repository := o.repository
+		if repository == "" {
+			if repository, o.err = defaultMavenRepository(); o.err != nil {
+				return
+			}
+		}
+		o.files, o.providers, o.err = resolveMavenProviders(repository, o.coordinates, o.dependencies)
+	})
+	if o.err != nil {
+		return o.err
+	}
+
+	// the providers are kept in the request, so Run finds them also when the option is wrapped
+	providers, err := requestProviders(req)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(append(providers, o.providers...))
+	if err != nil {
+		return err
+	}
+	req.Env[keycloakMavenProvidersEnv] = string(data)
+
+	for _, file := range o.files {
+		req.Files = append(req.Files, providerFile(file))
+	}
+
+	return nil
+}
+
+// requestProviders returns the providers WithMavenProviders added to the request.
+func requestProviders(req *testcontainers.GenericContainerRequest) ([]MavenProvider, error) {
+	var providers []MavenProvider
+	if data := req.Env[keycloakMavenProvidersEnv]; data != "" {
+		if err := json.Unmarshal([]byte(data), &providers); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", keycloakMavenProvidersEnv, err)
+		}
+	}
+	return providers, nil
+}
+
+// resolveMavenProviders returns the JARs of the coordinates, and of their dependencies if requested,
+// together with the factories registered by the provider JARs.
+func resolveMavenProviders(repository string, coordinates []string, dependencies bool) ([]string, []MavenProvider, error) {
+	resolver := newMavenResolver(repository)
+
+	var files []string
+	var providers []MavenProvider
+	seen := make(map[string]bool)
+	add := func(c mavenCoordinate) (string, error) {
+		file, err := resolver.artifact(c)
+		if err != nil {
+			return "", err
+		}
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+		return file, nil
+	}
+
+	for _, coordinate := range coordinates {
+		c, err := parseMavenCoordinate(coordinate)
+		if err != nil {
+			return nil, nil, err
+		}
+		file, err := add(c)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		found, err := scanProviders(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(found) == 0 {
+			return nil, nil, fmt.Errorf("%s does not register any keycloak provider in %s*", c, keycloakServicePrefix)
+		}
+		for i := range found {
+			found[i].Artifact = c.String()
+		}
+		providers = append(providers, found...)
+
+		if !dependencies {
+			continue
+		}
+		deps, err := resolver.dependencies(c)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, dep := range deps {
+			if _, err = add(dep); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return files, providers, nil
+}
+
+// scanProviders returns the factories the service files of the JAR register for Keycloak SPIs.
+func scanProviders(jar string) ([]MavenProvider, error) {
+	r, err := zip.OpenReader(jar)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var providers []MavenProvider
+	for _, f := range r.File {
+		if !strings.HasPrefix(f.Name, keycloakServicePrefix) {
+			continue
+		}
+		factories, err := readServiceFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("read %s of %s: %w", f.Name, jar, err)
+		}
+		for _, factory := range factories {
+			providers = append(providers, MavenProvider{
+				Factory: factory,
+				SPI:     strings.TrimPrefix(f.Name, "META-INF/services/"),
+				ID:      jarProviderID(r, factory),
+			})
+		}
+	}
+
+	return providers, nil
+}
+
+// jarProviderID returns the provider id of the factory class, following its superclasses in the JAR
+// if the getId method is inherited. The id is empty if it cannot be determined.
+func jarProviderID(r *zip.ReadCloser, class string) string {
+	for depth := 0; class != "" && depth < 8; depth++ {
+		f, err := r.Open(strings.ReplaceAll(class, ".", "/") + ".class")
+		if err != nil {
+			// the class or the superclass is not part of the JAR
+			return ""
+		}
+		id, found, superClass, err := classProviderID(f)
+		f.Close()
+		if err != nil {
+			// a class file the parser cannot read, e.g. of a newer Java version, leaves the id unknown
+			return ""
+		}
+		if found {
+			return id
+		}
+		class = superClass
+	}
+	return ""
+}
+
+func readServiceFile(f *zip.File) ([]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var factories []string
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			factories = append(factories, line)
+		}
+	}
+	return factories, scanner.Err()
+}
+
+// verifyProviders returns an error listing the providers that are not registered by Keycloak.
+func (k *KeycloakContainer) verifyProviders(ctx context.Context, providers []MavenProvider, logger log.Logger) error {
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return err
+	}
+	info, err := adminClient.GetServerInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	return unregisteredProviders(info, providers, logger)
+}
+
+// unregisteredProviders returns an error listing the providers that are not registered according to the server info.
+// Providers without id cannot be verified and are logged, unless no provider of their artifact has an id,
+// which is reported as error, since nothing would show that the artifact is loaded at all.
+func unregisteredProviders(info *ServerInfo, providers []MavenProvider, logger log.Logger) error {
+	registered := make(map[string]map[string]bool)
+	if info.Providers != nil {
+		for name, spi := range *info.Providers {
+			registered[name] = make(map[string]bool)
+			if spi.Providers == nil {
+				continue
+			}
+			for id := range *spi.Providers {
+				registered[name][id] = true
+			}
+		}
+	}
+	isRegistered := func(p MavenProvider) bool {
+		if spi, ok := providerSPIs[p.SPI]; ok {
+			return registered[spi][p.ID]
+		}
+		for _, ids := range registered {
+			if ids[p.ID] {
+				return true
+			}
+		}
+		return false
+	}
+
+	verifiable := make(map[string]bool)
+	for _, p := range providers {
+		verifiable[p.Artifact] = verifiable[p.Artifact] || p.ID != ""
+	}
+
+	var errs []error
+	for _, p := range providers {
+		switch {
+		case p.ID == "" && !verifiable[p.Artifact]:
+			errs = append(errs, fmt.Errorf("no provider of %s can be verified, the getId of %s (%s) does not return a string constant", p.Artifact, p.SPI, p.Factory))
+		case p.ID == "":
+			logger.Printf("provider %s (%s) of %s is not verified, its getId does not return a string constant", p.SPI, p.Factory, p.Artifact)
+		case !isRegistered(p):
+			errs = append(errs, fmt.Errorf("provider %s of %s (%s) of %s is not registered", p.ID, p.SPI, p.Factory, p.Artifact))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// classProviderID returns the provider id of a factory class file, if its getId method returns a string constant,
+// which is the case for factories like
+//
+//	public String getId() { return PROVIDER_ID; }
+//
+// since the compiler inlines constants. Otherwise, the id is empty. found reports whether the class declares getId,
+// if it does not, the method may be inherited from the returned superclass.
+// See https://docs.oracle.com/javase/specs/jvms/se21/html/jvms-4.html
+func classProviderID(r io.Reader) (id string, found bool, superClass string, err error) {
+	c := &classReader{r: bufio.NewReader(r)}
+	if c.u4() != 0xCAFEBABE {
+		return "", false, "", errors.New("not a class file")
+	}
+	c.skip(4) // version
+
+	count := int(c.u2())
+	utf8 := make(map[int]string)
+	strs := make(map[int]int)
+	classes := make(map[int]int)
+	for i := 1; i < count && c.err == nil; i++ {
+		switch tag := c.u1(); tag {
+		case 1: // Utf8
+			utf8[i] = string(c.bytes(int(c.u2())))
+		case 8: // String
+			strs[i] = int(c.u2())
+		case 7: // Class
+			classes[i] = int(c.u2())
+		case 16, 19, 20: // MethodType, Module, Package
+			c.skip(2)
+		case 15: // MethodHandle
+			c.skip(3)
+		case 3, 4, 9, 10, 11, 12, 17, 18: // Integer, Float, references, NameAndType, dynamic constants
+			c.skip(4)
+		case 5, 6: // Long and Double take two entries
+			c.skip(8)
+			i++
+		default:
+			return "", false, "", fmt.Errorf("unknown constant pool tag %d", tag)
+		}
+	}
+
+	c.skip(4) // access flags, this class
+	if super, ok := classes[int(c.u2())]; ok {
+		superClass = strings.ReplaceAll(utf8[super], "/", ".")
+	}
+	c.skip(2 * int(c.u2()))
+	for fields := int(c.u2()); fields > 0 && c.err == nil; fields-- {
+		c.skip(6)
+		c.skipAttributes()
+	}
+
+	for methods := int(c.u2()); methods > 0 && c.err == nil; methods-- {
+		c.skip(2)
+		name, descriptor := utf8[int(c.u2())], utf8[int(c.u2())]
+		if name != "getId" || descriptor != "()Ljava/lang/String;" {
+			c.skipAttributes()
+			continue
+		}
+		for attributes := int(c.u2()); attributes > 0 && c.err == nil; attributes-- {
+			attribute, data := utf8[int(c.u2())], c.bytes(int(c.u4()))
+			if attribute != "Code" || len(data) < 8 {
+				continue
+			}
+			code := data[8:]
+			if length := int(binary.BigEndian.Uint32(data[4:8])); length < len(code) {
+				code = code[:length]
+			}
+			// ldc or ldc_w of a String constant followed by areturn
+			var index int
+			switch {
+			case len(code) == 3 && code[0] == 0x12 && code[2] == 0xB0:
+				index = int(code[1])
+			case len(code) == 4 && code[0] == 0x13 && code[3] == 0xB0:
+				index = int(binary.BigEndian.Uint16(code[1:3]))
+			default:
+				return "", true, superClass, c.err
+			}
+			if s, ok := strs[index]; ok {
+				return utf8[s], true, superClass, c.err
+			}
+		}
+		return "", true, superClass, c.err
+	}
+
+	return "", false, superClass, c.err
+}
+
+// classReader reads the big-endian items of a class file and keeps the first error.
+type classReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (c *classReader) bytes(n int) []byte {
+	b := make([]byte, n)
+	if c.err == nil {
+		_, c.err = io.ReadFull(c.r, b)
+	}
+	return b
+}
+
+func (c *classReader) skip(n int) {
+	if c.err == nil {
+		_, c.err = c.r.Discard(n)
+	}
+}
+
+func (c *classReader) u1() uint8 {
+	return c.bytes(1)[0]
+}
+
+func (c *classReader) u2() uint16 {
+	return binary.BigEndian.Uint16(c.bytes(2))
+}
+
+func (c *classReader) u4() uint32 {
+	return binary.BigEndian.Uint32(c.bytes(4))
+}
+
+func (c *classReader) skipAttributes() {
+	for attributes := int(c.u2()); attributes > 0 && c.err == nil; attributes-- {
+		c.skip(2)
+		c.skip(int(c.u4()))
+	}
+}
@@ -0,0 +1,347 @@
+package keycloak
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestParseMavenCoordinate(t *testing.T) {
+	tests := []struct {
+		coordinate string
+		want       mavenCoordinate
+		wantErr    bool
+	}{
+		{
+			coordinate: "com.example:custom-provider:1.0.0",
+			want:       mavenCoordinate{GroupID: "com.example", ArtifactID: "custom-provider", Version: "1.0.0", Packaging: "jar"},
+		},
+		{
+			coordinate: "com.example:custom-provider:jar:shaded:1.0.0-SNAPSHOT",
+			want:       mavenCoordinate{GroupID: "com.example", ArtifactID: "custom-provider", Version: "1.0.0-SNAPSHOT", Packaging: "jar", Classifier: "shaded"},
+		},
+		{coordinate: "com.example:custom-provider", wantErr: true},
+		{coordinate: "com.example::1.0.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.coordinate, func(t *testing.T) {
+			got, err := parseMavenCoordinate(tt.coordinate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseMavenCoordinate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseMavenCoordinate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMavenProviders(t *testing.T) {
+	repository := t.TempDir()
+
+	writeMavenArtifact(t, repository, "com.example:parent:1.0.0", `<project>
+  <groupId>com.example</groupId>
+  <artifactId>parent</artifactId>
+  <version>1.0.0</version>
+  <properties><lib.version>2.0.0</lib.version></properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency><groupId>com.example</groupId><artifactId>lib</artifactId><version>${lib.version}</version></dependency>
+      <dependency><groupId>com.example</groupId><artifactId>container-lib</artifactId><version>1.0.0</version><scope>provided</scope></dependency>
+      <dependency>
+        <groupId>com.example</groupId><artifactId>transitive</artifactId><version>2.0.0</version>
+        <exclusions><exclusion><groupId>com.example</groupId><artifactId>managed-excluded</artifactId></exclusion></exclusions>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>`, nil)
+	writeMavenArtifact(t, repository, "com.example:custom-provider:1.0.0", `<project>
+  <parent><groupId>com.example</groupId><artifactId>parent</artifactId><version>1.0.0</version></parent>
+  <artifactId>custom-provider</artifactId>
+  <dependencies>
+    <dependency><groupId>org.keycloak</groupId><artifactId>keycloak-services</artifactId><version>26.0.0</version><scope>provided</scope></dependency>
+    <dependency>
+      <groupId>com.example</groupId><artifactId>lib</artifactId>
+      <exclusions><exclusion><groupId>com.example</groupId><artifactId>excluded</artifactId></exclusion></exclusions>
+    </dependency>
+    <dependency><groupId>com.example</groupId><artifactId>container-lib</artifactId></dependency>
+    <dependency><groupId>com.example</groupId><artifactId>optional</artifactId><version>1.0.0</version><optional>true</optional></dependency>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.13.2</version><scope>test</scope></dependency>
+  </dependencies>
+</project>`, map[string][]byte{
+		"META-INF/services/org.keycloak.authentication.AuthenticatorFactory": []byte("# authenticators\ncom.example.CustomAuthenticatorFactory\n" +
+			"com.example.InheritedAuthenticatorFactory\ncom.example.ExternalAuthenticatorFactory\ncom.example.BrokenAuthenticatorFactory\n"),
+		"com/example/CustomAuthenticatorFactory.class":    factoryClass("com/example/CustomAuthenticatorFactory", "java/lang/Object", "custom-authenticator"),
+		"com/example/InheritedAuthenticatorFactory.class": factoryClass("com/example/InheritedAuthenticatorFactory", "com/example/CustomAuthenticatorFactory", ""),
+		"com/example/ExternalAuthenticatorFactory.class":  factoryClass("com/example/ExternalAuthenticatorFactory", "org/example/BaseAuthenticatorFactory", ""),
+		"com/example/BrokenAuthenticatorFactory.class":    []byte("not a class file"),
+	})
+	writeMavenArtifact(t, repository, "com.example:lib:2.0.0", `<project>
+  <groupId>com.example</groupId>
+  <artifactId>lib</artifactId>
+  <version>2.0.0</version>
+  <dependencies>
+    <dependency><groupId>com.example</groupId><artifactId>excluded</artifactId><version>1.0.0</version></dependency>
+    <dependency><groupId>com.example</groupId><artifactId>transitive</artifactId><version>${project.version}</version><scope>runtime</scope></dependency>
+  </dependencies>
+</project>`, nil)
+	writeMavenArtifact(t, repository, "com.example:transitive:2.0.0", `<project>
+  <dependencies>
+    <dependency><groupId>com.example</groupId><artifactId>managed-excluded</artifactId><version>1.0.0</version></dependency>
+  </dependencies>
+</project>`, nil)
+
+	files, providers, err := resolveMavenProviders(repository, []string{"com.example:custom-provider:1.0.0"}, true)
+	if err != nil {
+		t.Errorf("resolveMavenProviders() error = %v", err)
+		return
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+	if want := "[custom-provider-1.0.0.jar lib-2.0.0.jar transitive-2.0.0.jar]"; fmt.Sprint(names) != want {
+		t.Errorf("resolveMavenProviders() files = %v, want %v", names, want)
+	}
+
+	// the id of a factory that inherits getId from a class outside of the JAR or that cannot be parsed is unknown
+	want := []MavenProvider{{
+		Artifact: "com.example:custom-provider:1.0.0",
+		Factory:  "com.example.CustomAuthenticatorFactory",
+		SPI:      "org.keycloak.authentication.AuthenticatorFactory",
+		ID:       "custom-authenticator",
+	}, {
+		Artifact: "com.example:custom-provider:1.0.0",
+		Factory:  "com.example.InheritedAuthenticatorFactory",
+		SPI:      "org.keycloak.authentication.AuthenticatorFactory",
+		ID:       "custom-authenticator",
+	}, {
+		Artifact: "com.example:custom-provider:1.0.0",
+		Factory:  "com.example.ExternalAuthenticatorFactory",
+		SPI:      "org.keycloak.authentication.AuthenticatorFactory",
+	}, {
+		Artifact: "com.example:custom-provider:1.0.0",
+		Factory:  "com.example.BrokenAuthenticatorFactory",
+		SPI:      "org.keycloak.authentication.AuthenticatorFactory",
+	}}
+	if fmt.Sprint(providers) != fmt.Sprint(want) {
+		t.Errorf("resolveMavenProviders() providers = %v, want %v", providers, want)
+	}
+
+	files, _, err = resolveMavenProviders(repository, []string{"com.example:custom-provider:1.0.0"}, false)
+	if err != nil || len(files) != 1 {
+		t.Errorf("resolveMavenProviders() without dependencies = %v, error = %v", files, err)
+	}
+
+	_, _, err = resolveMavenProviders(repository, []string{"com.example:missing:1.0.0"}, false)
+	if err == nil || !strings.Contains(err.Error(), "not found in local maven repository") {
+		t.Errorf("resolveMavenProviders() with missing artifact error = %v", err)
+	}
+
+	_, _, err = resolveMavenProviders(repository, []string{"com.example:transitive:2.0.0"}, false)
+	if err == nil {
+		t.Errorf("resolveMavenProviders() without providers error = nil")
+	}
+}
+
+func TestUnregisteredProviders(t *testing.T) {
+	info := &ServerInfo{Providers: &map[string]SPIInfo{
+		"authenticator":   {Providers: &map[string]ProviderInfo{"custom-authenticator": {}}},
+		"protocol-mapper": {Providers: &map[string]ProviderInfo{"custom-mapper": {}}},
+	}}
+	provider := func(spi, id string) MavenProvider {
+		return MavenProvider{Artifact: "com.example:custom-provider:1.0.0", Factory: "com.example.CustomFactory", SPI: spi, ID: id}
+	}
+
+	var logger recordingLogger
+	err := unregisteredProviders(info, []MavenProvider{
+		provider("org.keycloak.authentication.AuthenticatorFactory", "custom-authenticator"),
+		// registered, but for another SPI
+		provider("org.keycloak.authentication.AuthenticatorFactory", "custom-mapper"),
+		// the SPI of the factory is not known, so the providers of all SPIs are searched
+		provider("org.keycloak.example.CustomProviderFactory", "custom-mapper"),
+		provider("org.keycloak.authentication.AuthenticatorFactory", ""),
+	}, &logger)
+
+	want := "provider custom-mapper of org.keycloak.authentication.AuthenticatorFactory (com.example.CustomFactory) of com.example:custom-provider:1.0.0 is not registered"
+	if err == nil || err.Error() != want {
+		t.Errorf("unregisteredProviders() error = %v, want %v", err, want)
+	}
+	if len(logger) != 1 || !strings.Contains(logger[0], "is not verified") {
+		t.Errorf("unregisteredProviders() logs = %v, want the provider without id", logger)
+	}
+
+	unverifiable := MavenProvider{Artifact: "com.example:other-provider:1.0.0", Factory: "com.example.OtherFactory", SPI: "org.keycloak.authentication.AuthenticatorFactory"}
+	err = unregisteredProviders(info, []MavenProvider{provider("org.keycloak.authentication.AuthenticatorFactory", "custom-authenticator"), unverifiable}, &logger)
+	if err == nil || !strings.Contains(err.Error(), "no provider of com.example:other-provider:1.0.0 can be verified") {
+		t.Errorf("unregisteredProviders() without verifiable provider error = %v", err)
+	}
+}
+
+func TestMavenProvidersOption_Legacy(t *testing.T) {
+	repository := t.TempDir()
+	writeMavenArtifact(t, repository, "com.example:custom-provider:1.0.0", "<project/>", map[string][]byte{
+		"META-INF/services/org.keycloak.authentication.AuthenticatorFactory": []byte("com.example.CustomAuthenticatorFactory\n"),
+		"com/example/CustomAuthenticatorFactory.class":                       factoryClass("com/example/CustomAuthenticatorFactory", "java/lang/Object", "custom-authenticator"),
+	})
+
+	req := &testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image: "jboss/keycloak:16.1.1",
+			Env:   map[string]string{},
+		},
+	}
+	if err := (&mavenProvidersOption{coordinates: []string{"com.example:custom-provider:1.0.0"}, repository: repository}).Customize(req); err != nil {
+		t.Errorf("Customize() error = %v", err)
+		return
+	}
+	c, err := requestConfig(req)
+	if err != nil {
+		t.Errorf("requestConfig() error = %v", err)
+		return
+	}
+	if err = applyLegacy(req, c); err != nil {
+		t.Errorf("applyLegacy() error = %v", err)
+		return
+	}
+	if len(req.Files) != 1 || req.Files[0].ContainerFilePath != legacyProviders+"custom-provider-1.0.0.jar" {
+		t.Errorf("Files = %v, want the JAR in %s", req.Files, legacyProviders)
+	}
+}
+
+func TestKeycloakContainer_WithMavenProviders(t *testing.T) {
+	ctx := context.Background()
+
+	// Keycloak does not load factories of unknown SPIs, so the provider is never registered
+	repository := t.TempDir()
+	writeMavenArtifact(t, repository, "com.example:missing-provider:1.0.0", "<project/>", map[string][]byte{
+		"META-INF/services/org.keycloak.example.MissingProviderFactory": []byte("com.example.MissingProviderFactory\n"),
+		"com/example/MissingProviderFactory.class":                      factoryClass("com/example/MissingProviderFactory", "java/lang/Object", "missing-provider"),
+	})
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		&mavenProvidersOption{coordinates: []string{"com.example:missing-provider:1.0.0"}, repository: repository},
+	)
+	testcontainers.CleanupContainer(t, container)
+	if err == nil || !strings.Contains(err.Error(), "provider missing-provider of org.keycloak.example.MissingProviderFactory") {
+		t.Errorf("Run() with a missing provider error = %v", err)
+	}
+}
+
+// recordingLogger records the messages logged by the code under test.
+type recordingLogger []string
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+}
+
+// writeMavenArtifact installs the POM and, if there are files, the JAR of the artifact into the repository.
+func writeMavenArtifact(t *testing.T, repository, coordinate, pom string, files map[string][]byte) {
+	t.Helper()
+
+	c, err := parseMavenCoordinate(coordinate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(c.path(repository, "pom")), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(c.path(repository, "pom"), []byte(pom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var jar bytes.Buffer
+	w := zip.NewWriter(&jar)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = f.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(c.path(repository, "jar"), jar.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// factoryClass returns a class file of the class extending superClass with a getId method that returns the id,
+// as compiled from
+//
+//	public String getId() { return "id"; }
+//
+// If id is empty, the class has no getId method.
+func factoryClass(class, superClass, id string) []byte {
+	var b bytes.Buffer
+	u1 := func(v uint8) { b.WriteByte(v) }
+	u2 := func(v uint16) { _ = binary.Write(&b, binary.BigEndian, v) }
+	u4 := func(v uint32) { _ = binary.Write(&b, binary.BigEndian, v) }
+	utf8 := func(s string) { u1(1); u2(uint16(len(s))); b.WriteString(s) }
+
+	u4(0xCAFEBABE)
+	u2(0)
+	u2(65)
+
+	u2(12)
+	utf8(class) // 1
+	u1(7)       // 2 Class
+	u2(1)
+	utf8(superClass) // 3
+	u1(7)            // 4 Class
+	u2(3)
+	utf8("getId")                // 5
+	utf8("()Ljava/lang/String;") // 6
+	utf8("Code")                 // 7
+	utf8(id)                     // 8
+	u1(8)                        // 9 String
+	u2(8)
+	u1(5) // 10 Long, which takes two entries
+	u4(0)
+	u4(42)
+
+	u2(0x21) // access flags
+	u2(2)    // this class
+	u2(4)    // super class
+	u2(0)    // interfaces
+	u2(0)    // fields
+
+	if id == "" {
+		u2(0) // methods
+		u2(0) // attributes
+		return b.Bytes()
+	}
+
+	u2(1) // methods
+	u2(0x01)
+	u2(5)
+	u2(6)
+	u2(1) // attributes
+	u2(7)
+	u4(15)
+	u2(1) // max stack
+	u2(1) // max locals
+	u4(3) // code length
+	u1(0x12)
+	u1(9)
+	u1(0xB0)
+	u2(0) // exception table
+	u2(0) // attributes
+
+	u2(0) // attributes
+	return b.Bytes()
+}
@@ -0,0 +1,302 @@
+package keycloak
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MockOIDCProviderClientID is the client id Keycloak uses to authenticate at a MockOIDCProvider.
+	MockOIDCProviderClientID = "keycloak"
+	// MockOIDCProviderClientSecret is the client secret Keycloak uses to authenticate at a MockOIDCProvider.
+	MockOIDCProviderClientSecret = "keycloak-secret"
+
+	mockOIDCProviderKeyID       = "mock-oidc-provider"
+	mockOIDCProviderSubject     = "mock-user"
+	mockOIDCProviderDisplayName = "Mock OIDC Provider"
+	mockOIDCProviderTokenTTL    = 5 * time.Minute
+	oidcIdentityProviderID      = "oidc"
+)
+
+// MockOIDCProvider is a minimal OpenID Connect provider running in the test process,
+// to be used as an upstream identity provider of Keycloak in brokering tests.
+// Authorization requests are consented automatically, so broker login flows can be driven headlessly.
+type MockOIDCProvider struct {
+	listener net.Listener
+	server   *http.Server
+	key      *rsa.PrivateKey
+
+	mu     sync.Mutex
+	claims map[string]interface{}
+	codes  map[string]mockAuthorization
+	tokens map[string]map[string]interface{}
+}
+
+type mockAuthorization struct {
+	clientID string
+	nonce    string
+}
+
+// NewMockOIDCProvider starts a MockOIDCProvider listening on a random local port,
+// issuing tokens for a user with the given claims. The sub claim defaults to mock-user.
+func NewMockOIDCProvider(claims map[string]interface{}) (*MockOIDCProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	p := &MockOIDCProvider{
+		listener: l,
+		key:      key,
+		codes:    make(map[string]mockAuthorization),
+		tokens:   make(map[string]map[string]interface{}),
+	}
+	p.SetClaims(claims)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("/authorize", p.handleAuthorize)
+	mux.HandleFunc("/token", p.handleToken)
+	mux.HandleFunc("/userinfo", p.handleUserInfo)
+	mux.HandleFunc("/jwks", p.handleJWKS)
+	p.server = &http.Server{Handler: mux}
+
+	go func() {
+		_ = p.server.Serve(l)
+	}()
+
+	return p, nil
+}
+
+// URL returns the URL of the MockOIDCProvider on the host, which is also its issuer.
+func (p *MockOIDCProvider) URL() string {
+	return "http://" + p.listener.Addr().String()
+}
+
+// SetClaims replaces the claims of the user tokens are issued for.
+func (p *MockOIDCProvider) SetClaims(claims map[string]interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.claims = map[string]interface{}{"sub": mockOIDCProviderSubject}
+	for k, v := range claims {
+		p.claims[k] = v
+	}
+}
+
+// Close stops the MockOIDCProvider.
+func (p *MockOIDCProvider) Close() error {
+	return p.server.Close()
+}
+
+// IdentityProvider returns the configuration of an OIDC identity provider with the given alias
+// delegating to the MockOIDCProvider. The browser is redirected to the URL of the provider on the host,
+// while Keycloak reaches its token, userinfo and JWKS endpoints via backChannelURL,
+// usually the result of KeycloakContainer.GetHostCallbackURL(p.URL()).
+func (p *MockOIDCProvider) IdentityProvider(alias, backChannelURL string) IdentityProvider {
+	backChannelURL = strings.TrimSuffix(backChannelURL, "/")
+	enabled := true
+	trustEmail := true
+	providerID := oidcIdentityProviderID
+	displayName := mockOIDCProviderDisplayName
+
+	return IdentityProvider{
+		Alias:       &alias,
+		DisplayName: &displayName,
+		ProviderID:  &providerID,
+		Enabled:     &enabled,
+		TrustEmail:  &trustEmail,
+		Config: &map[string]string{
+			"issuer":            p.URL(),
+			"authorizationUrl":  p.URL() + "/authorize",
+			"tokenUrl":          backChannelURL + "/token",
+			"userInfoUrl":       backChannelURL + "/userinfo",
+			"jwksUrl":           backChannelURL + "/jwks",
+			"useJwksUrl":        "true",
+			"validateSignature": "true",
+			"clientId":          MockOIDCProviderClientID,
+			"clientSecret":      MockOIDCProviderClientSecret,
+			"clientAuthMethod":  "client_secret_post",
+			"defaultScope":      "openid profile email",
+			"syncMode":          "IMPORT",
+		},
+	}
+}
+
+// RegisterMockOIDCProvider registers the MockOIDCProvider as identity provider with the given alias in the realm.
+// See MockOIDCProvider.IdentityProvider for the meaning of backChannelURL.
+func (a *AdminClient) RegisterMockOIDCProvider(ctx context.Context, realm, alias string, p *MockOIDCProvider, backChannelURL string) error {
+	return a.CreateIdentityProvider(ctx, realm, p.IdentityProvider(alias, backChannelURL))
+}
+
+func (p *MockOIDCProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.URL(),
+		"authorization_endpoint":                p.URL() + "/authorize",
+		"token_endpoint":                        p.URL() + "/token",
+		"userinfo_endpoint":                     p.URL() + "/userinfo",
+		"jwks_uri":                              p.URL() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_post", "client_secret_basic"},
+	})
+}
+
+func (p *MockOIDCProvider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURI.String() == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	p.mu.Lock()
+	p.codes[code] = mockAuthorization{clientID: q.Get("client_id"), nonce: q.Get("nonce")}
+	p.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	if state := q.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+	redirectURI.RawQuery = params.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (p *MockOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != MockOIDCProviderClientID || clientSecret != MockOIDCProviderClientSecret {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	p.mu.Lock()
+	authz, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+	if !ok || authz.clientID != clientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := p.userClaims()
+	claims["iss"] = p.URL()
+	claims["aud"] = clientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(mockOIDCProviderTokenTTL).Unix()
+	if authz.nonce != "" {
+		claims["nonce"] = authz.nonce
+	}
+
+	idToken, err := signJWT(mockOIDCProviderKeyID, p.key, claims)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	accessToken, err := randomString()
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	p.mu.Lock()
+	p.tokens[accessToken] = p.userClaimsLocked()
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"id_token":     idToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(mockOIDCProviderTokenTTL.Seconds()),
+	})
+}
+
+func (p *MockOIDCProvider) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	p.mu.Lock()
+	claims, ok := p.tokens[accessToken]
+	p.mu.Unlock()
+	if !ok {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_token")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, claims)
+}
+
+func (p *MockOIDCProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, JSONWebKeySet{
+		Keys: []JSONWebKey{newJSONWebKey(mockOIDCProviderKeyID, &p.key.PublicKey)},
+	})
+}
+
+func (p *MockOIDCProvider) userClaims() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.userClaimsLocked()
+}
+
+func (p *MockOIDCProvider) userClaimsLocked() map[string]interface{} {
+	claims := make(map[string]interface{}, len(p.claims))
+	for k, v := range p.claims {
+		claims[k] = v
+	}
+	return claims
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,200 @@
+package keycloak
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestMockOIDCProvider(t *testing.T) {
+	provider, err := NewMockOIDCProvider(map[string]interface{}{
+		"email": "jane@example.com",
+	})
+	if err != nil {
+		t.Errorf("NewMockOIDCProvider() error = %v", err)
+		return
+	}
+	defer provider.Close()
+
+	noRedirect := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := noRedirect.Get(provider.URL() + "/authorize?" + url.Values{
+		"client_id":     {MockOIDCProviderClientID},
+		"redirect_uri":  {"http://localhost/callback"},
+		"response_type": {"code"},
+		"state":         {"xyz"},
+		"nonce":         {"n-0S6"},
+	}.Encode())
+	if err != nil {
+		t.Errorf("authorize error = %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || location.Query().Get("state") != "xyz" || location.Query().Get("code") == "" {
+		t.Errorf("authorize redirect = %v", resp.Header.Get("Location"))
+		return
+	}
+
+	resp, err = http.PostForm(provider.URL()+"/token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {location.Query().Get("code")},
+		"client_id":     {MockOIDCProviderClientID},
+		"client_secret": {MockOIDCProviderClientSecret},
+	})
+	if err != nil {
+		t.Errorf("token error = %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var token Token
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		t.Errorf("Decode() error = %v", err)
+		return
+	}
+
+	resp, err = http.Get(provider.URL() + "/jwks")
+	if err != nil {
+		t.Errorf("jwks error = %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var jwks JSONWebKeySet
+	if err = json.NewDecoder(resp.Body).Decode(&jwks); err != nil || len(jwks.Keys) != 1 {
+		t.Errorf("Decode() error = %v", err)
+		return
+	}
+
+	n, _ := base64.RawURLEncoding.DecodeString(jwks.Keys[0].N)
+	e, _ := base64.RawURLEncoding.DecodeString(jwks.Keys[0].E)
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	parts := strings.Split(token.IDToken, ".")
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("id_token signature error = %v", err)
+		return
+	}
+
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]interface{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		t.Errorf("Unmarshal() error = %v", err)
+		return
+	}
+	if claims["iss"] != provider.URL() || claims["nonce"] != "n-0S6" || claims["sub"] != "mock-user" || claims["email"] != "jane@example.com" {
+		t.Errorf("id_token claims = %v", claims)
+		return
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, provider.URL()+"/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Errorf("userinfo error = %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var userInfo map[string]interface{}
+	if err = json.NewDecoder(resp.Body).Decode(&userInfo); err != nil || userInfo["email"] != "jane@example.com" {
+		t.Errorf("userinfo = %v, error = %v", userInfo, err)
+	}
+}
+
+func TestAdminClient_RegisterMockOIDCProvider(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := NewMockOIDCProvider(map[string]interface{}{
+		"preferred_username": "jane",
+		"email":              "jane@example.com",
+		"given_name":         "Jane",
+		"family_name":        "Doe",
+	})
+	if err != nil {
+		t.Errorf("NewMockOIDCProvider() error = %v", err)
+		return
+	}
+	defer provider.Close()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+		WithHostCallbackURLs(provider.URL()),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	backChannelURL, err := container.GetHostCallbackURL(provider.URL())
+	if err != nil {
+		t.Errorf("GetHostCallbackURL() error = %v", err)
+		return
+	}
+
+	if err = adminClient.RegisterMockOIDCProvider(ctx, realm, "mock", provider, backChannelURL); err != nil {
+		t.Errorf("RegisterMockOIDCProvider() error = %v", err)
+		return
+	}
+
+	cfg, err := container.GetOIDCConfig(ctx, realm, client)
+	if err != nil {
+		t.Errorf("GetOIDCConfig() error = %v", err)
+		return
+	}
+
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Host == "localhost" {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	resp, err := browser.Get(cfg.AuthURL + "?" + url.Values{
+		"client_id":     {client},
+		"redirect_uri":  {"http://localhost/callback"},
+		"response_type": {"code"},
+		"scope":         {"openid"},
+		"kc_idp_hint":   {"mock"},
+	}.Encode())
+	if err != nil {
+		t.Errorf("broker login error = %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || location.Query().Get("code") == "" {
+		t.Errorf("broker login status = %v, location = %v", resp.StatusCode, resp.Header.Get("Location"))
+	}
+}
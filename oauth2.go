@@ -0,0 +1,190 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcEnvIssuerURL     = "OIDC_ISSUER_URL"
+	oidcEnvAuthURL       = "OIDC_AUTH_URL"
+	oidcEnvTokenURL      = "OIDC_TOKEN_URL"
+	oidcEnvUserInfoURL   = "OIDC_USERINFO_URL"
+	oidcEnvJWKSURL       = "OIDC_JWKS_URL"
+	oidcEnvEndSessionURL = "OIDC_END_SESSION_URL"
+	oidcEnvClientID      = "OIDC_CLIENT_ID"
+	oidcEnvClientSecret  = "OIDC_CLIENT_SECRET"
+	oidcEnvRedirectURL   = "OIDC_REDIRECT_URL"
+	oidcEnvScopes        = "OIDC_SCOPES"
+	oidcDefaultScope     = "openid"
+)
+
+// OIDCConfig holds the OpenID Connect endpoints of a realm
+// together with the credentials of one of its clients.
+type OIDCConfig struct {
+	Issuer        string
+	AuthURL       string
+	TokenURL      string
+	UserInfoURL   string
+	JWKSURL       string
+	EndSessionURL string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+}
+
+// GetOIDCConfig returns the OIDCConfig of the client with the given clientID in the realm.
+// The client secret and redirect URL are read via the admin API.
+func (k *KeycloakContainer) GetOIDCConfig(ctx context.Context, realm, clientID string) (*OIDCConfig, error) {
+	authServerURL, err := k.GetAuthServerURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.oidcConfig(ctx, authServerURL, realm, clientID)
+}
+
+// GetInternalOIDCConfig is like GetOIDCConfig, but the endpoints point to the URL
+// returned by GetInternalAuthServerURL, for applications running in the container network.
+func (k *KeycloakContainer) GetInternalOIDCConfig(ctx context.Context, realm, clientID string) (*OIDCConfig, error) {
+	authServerURL, err := k.GetInternalAuthServerURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.oidcConfig(ctx, authServerURL, realm, clientID)
+}
+
+// GetOAuth2Config returns an oauth2.Config for the client with the given clientID in the realm.
+// The openid scope is always requested, additional scopes are appended to it.
+func (k *KeycloakContainer) GetOAuth2Config(ctx context.Context, realm, clientID string, scopes ...string) (*oauth2.Config, error) {
+	cfg, err := k.GetOIDCConfig(ctx, realm, clientID)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Scopes = append(cfg.Scopes, scopes...)
+
+	return cfg.OAuth2Config(), nil
+}
+
+// OAuth2Config converts the OIDCConfig into an oauth2.Config.
+func (c *OIDCConfig) OAuth2Config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  c.AuthURL,
+			TokenURL: c.TokenURL,
+		},
+		RedirectURL: c.RedirectURL,
+		Scopes:      append([]string(nil), c.Scopes...),
+	}
+}
+
+// Env returns the OIDCConfig as OIDC_* environment variables
+// suitable for injecting into an application process or container.
+func (c *OIDCConfig) Env() map[string]string {
+	return map[string]string{
+		oidcEnvIssuerURL:     c.Issuer,
+		oidcEnvAuthURL:       c.AuthURL,
+		oidcEnvTokenURL:      c.TokenURL,
+		oidcEnvUserInfoURL:   c.UserInfoURL,
+		oidcEnvJWKSURL:       c.JWKSURL,
+		oidcEnvEndSessionURL: c.EndSessionURL,
+		oidcEnvClientID:      c.ClientID,
+		oidcEnvClientSecret:  c.ClientSecret,
+		oidcEnvRedirectURL:   c.RedirectURL,
+		oidcEnvScopes:        strings.Join(c.Scopes, " "),
+	}
+}
+
+// WriteEnvFile writes the environment variables returned by Env to the file at path
+// in KEY=VALUE format, one variable per line.
+func (c *OIDCConfig) WriteEnvFile(path string) error {
+	env := c.Env()
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, env[key])
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+func (k *KeycloakContainer) oidcConfig(ctx context.Context, authServerURL, realm, clientID string) (*OIDCConfig, error) {
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := adminClient.GetClient(realm, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := newOIDCConfig(authServerURL, realm)
+	cfg.ClientID = clientID
+	cfg.RedirectURL = redirectURL(c)
+
+	// the issuer does not depend on the URL the token endpoint is reached with if a hostname is pinned
+	if k.hostname != "" {
+		cfg.Issuer = newOIDCConfig(k.hostname, realm).Issuer
+	}
+
+	if c.PublicClient == nil || !*c.PublicClient {
+		secret, err := adminClient.GetClientSecret(ctx, realm, clientID)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientSecret = secret
+	}
+
+	return cfg, nil
+}
+
+func newOIDCConfig(authServerURL, realm string) *OIDCConfig {
+	issuer := strings.TrimSuffix(authServerURL, "/") + "/realms/" + url.PathEscape(realm)
+	endpoint := issuer + "/protocol/openid-connect"
+
+	return &OIDCConfig{
+		Issuer:        issuer,
+		AuthURL:       endpoint + "/auth",
+		TokenURL:      endpoint + "/token",
+		UserInfoURL:   endpoint + "/userinfo",
+		JWKSURL:       endpoint + "/certs",
+		EndSessionURL: endpoint + "/logout",
+		Scopes:        []string{oidcDefaultScope},
+	}
+}
+
+// redirectURL returns the first redirect URI of the client that is usable as-is,
+// i.e. not a wildcard pattern. Relative URIs are resolved against the client root URL.
+func redirectURL(c *Client) string {
+	if c.RedirectURIs == nil {
+		return ""
+	}
+
+	for _, uri := range *c.RedirectURIs {
+		if uri == "" || strings.Contains(uri, "*") {
+			continue
+		}
+		if strings.HasPrefix(uri, "/") && c.RootURL != nil {
+			return strings.TrimSuffix(*c.RootURL, "/") + uri
+		}
+		return uri
+	}
+
+	return ""
+}
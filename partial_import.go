@@ -0,0 +1,93 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+// PartialImportPolicy defines what happens if an imported resource already exists.
+type PartialImportPolicy string
+
+// Partial import policies.
+const (
+	// PartialImportSkip keeps existing resources.
+	PartialImportSkip PartialImportPolicy = "SKIP"
+	// PartialImportOverwrite replaces existing resources.
+	PartialImportOverwrite PartialImportPolicy = "OVERWRITE"
+	// PartialImportFail aborts the whole import.
+	PartialImportFail PartialImportPolicy = "FAIL"
+)
+
+// PartialImportResults is the outcome of a partial import.
+type PartialImportResults struct {
+	Added       int                   `json:"added"`
+	Skipped     int                   `json:"skipped"`
+	Overwritten int                   `json:"overwritten"`
+	Results     []PartialImportResult `json:"results"`
+}
+
+// PartialImportResult is the outcome of importing a single resource.
+type PartialImportResult struct {
+	// Action is one of ADDED, SKIPPED or OVERWRITTEN.
+	Action       string `json:"action"`
+	ResourceType string `json:"resourceType"`
+	ResourceName string `json:"resourceName"`
+	ID           string `json:"id"`
+}
+
+// PartialExportOptions selects what is exported by PartialExport besides the realm settings.
+type PartialExportOptions struct {
+	GroupsAndRoles bool
+	Clients        bool
+}
+
+// PartialImport imports the users, clients, groups, roles and identity providers of data,
+// a realm representation in JSON like an exported realm file, into the existing realm.
+// Existing resources are handled according to policy.
+// See https://www.keycloak.org/docs/latest/server_admin/#_partial-import
+func (a *AdminClient) PartialImport(ctx context.Context, realm string, data []byte, policy PartialImportPolicy) (*PartialImportResults, error) {
+	var rep map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return nil, err
+	}
+
+	ifResourceExists, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	rep["ifResourceExists"] = ifResourceExists
+
+	var results PartialImportResults
+	if err = a.doRequest(ctx, http.MethodPost, "/"+url.PathEscape(realm)+"/partialImport", rep, &results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// PartialImportFile is like PartialImport, but reads the realm representation from the file at path.
+func (a *AdminClient) PartialImportFile(ctx context.Context, realm, path string, policy PartialImportPolicy) (*PartialImportResults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return a.PartialImport(ctx, realm, data, policy)
+}
+
+// PartialExport returns the realm representation in JSON, optionally including its groups and roles and its clients.
+// Users are never exported and secrets are masked.
+func (a *AdminClient) PartialExport(ctx context.Context, realm string, opts PartialExportOptions) ([]byte, error) {
+	params := url.Values{
+		"exportGroupsAndRoles": {strconv.FormatBool(opts.GroupsAndRoles)},
+		"exportClients":        {strconv.FormatBool(opts.Clients)},
+	}
+
+	var data json.RawMessage
+	if err := a.doRequest(ctx, http.MethodPost, "/"+url.PathEscape(realm)+"/partial-export"+encodeQuery(params), nil, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
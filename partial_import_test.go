@@ -0,0 +1,93 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestAdminClient_PartialImport(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	fixture := []byte(`{
+		"users": [{"username": "fixture-user", "enabled": true}],
+		"clients": [{"clientId": "test-app", "enabled": true}, {"clientId": "fixture-app", "enabled": true}]
+	}`)
+
+	tests := []struct {
+		name    string
+		policy  PartialImportPolicy
+		added   int
+		skipped int
+		wantErr bool
+	}{
+		{
+			name:    "Skip",
+			policy:  PartialImportSkip,
+			added:   2,
+			skipped: 1,
+		},
+		{
+			name:    "Fail",
+			policy:  PartialImportFail,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := adminClient.PartialImport(ctx, realm, fixture, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PartialImport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if results.Added != tt.added || results.Skipped != tt.skipped || len(results.Results) != tt.added+tt.skipped {
+				t.Errorf("PartialImport() = %+v", results)
+			}
+		})
+	}
+
+	data, err := adminClient.PartialExport(ctx, realm, PartialExportOptions{Clients: true})
+	if err != nil {
+		t.Errorf("PartialExport() error = %v", err)
+		return
+	}
+
+	var export struct {
+		Clients []Client `json:"clients"`
+	}
+	if err = json.Unmarshal(data, &export); err != nil {
+		t.Errorf("Unmarshal() error = %v", err)
+		return
+	}
+
+	found := false
+	for _, c := range export.Clients {
+		found = found || *c.ClientID == "fixture-app"
+	}
+	if !found {
+		t.Errorf("PartialExport() has no fixture-app client")
+	}
+}
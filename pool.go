@@ -0,0 +1,181 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// poolAcquireTimeout is how long Pool.Acquire waits for an available KeycloakContainer.
+const poolAcquireTimeout = time.Minute
+
+// Pool is a set of KeycloakContainer instances started with the same options,
+// handed out to tests one at a time, so parallel tests do not share a container.
+type Pool struct {
+	containers []*KeycloakContainer
+	snapshots  map[*KeycloakContainer][]*RealmSnapshot
+	available  chan *KeycloakContainer
+}
+
+// NewPool starts size KeycloakContainer instances concurrently with the given options
+// and snapshots their realms, so they can be reset after each test.
+// The options must not include WithReuse, which would make all instances the same container.
+func NewPool(ctx context.Context, size int, img string, opts ...testcontainers.ContainerCustomizer) (*Pool, error) {
+	if size < 1 {
+		return nil, errors.New("pool size must be at least 1")
+	}
+
+	p := &Pool{
+		containers: make([]*KeycloakContainer, size),
+		snapshots:  make(map[*KeycloakContainer][]*RealmSnapshot, size),
+		available:  make(chan *KeycloakContainer, size),
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make([]error, size)
+	for i := 0; i < size; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			k, err := Run(ctx, img, opts...)
+			p.containers[i] = k
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			snapshots, err := k.snapshotRealms(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			mu.Lock()
+			p.snapshots[k] = snapshots
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, errors.Join(err, p.Terminate(ctx))
+	}
+
+	for _, k := range p.containers {
+		p.available <- k
+	}
+
+	return p, nil
+}
+
+// Acquire blocks until a KeycloakContainer of the pool is available and hands it out to the test.
+// When the test finishes, the realms of the container are reset to their state after startup,
+// realms created by the test are deleted and the container is returned to the pool.
+// The master realm is not reset, so tests must not leave changes to it, e.g. admin users or clients,
+// that other tests would see.
+// The test fails if no container becomes available within a minute, e.g. because a test acquires
+// more than one or the pool is smaller than the number of parallel tests.
+func (p *Pool) Acquire(t testing.TB) *KeycloakContainer {
+	t.Helper()
+
+	var k *KeycloakContainer
+	select {
+	case k = <-p.available:
+	case <-t.Context().Done():
+		t.Fatalf("no KeycloakContainer of the pool available: %v", t.Context().Err())
+	case <-time.After(poolAcquireTimeout):
+		t.Fatalf("no KeycloakContainer of the pool available after %s, "+
+			"the test may hold one already or the pool may be smaller than the number of parallel tests", poolAcquireTimeout)
+	}
+	t.Cleanup(func() {
+		if err := p.reset(context.Background(), k); err != nil {
+			t.Errorf("reset KeycloakContainer: %v", err)
+		}
+		p.available <- k
+	})
+
+	return k
+}
+
+// Terminate terminates all containers of the pool.
+func (p *Pool) Terminate(ctx context.Context) error {
+	var errs []error
+	for _, k := range p.containers {
+		if k != nil {
+			errs = append(errs, k.Terminate(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Pool) reset(ctx context.Context, k *KeycloakContainer) error {
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	realms, err := adminClient.GetRealms(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshots := p.snapshots[k]
+	for _, realm := range realms {
+		if realm == masterRealm || slices.ContainsFunc(snapshots, func(s *RealmSnapshot) bool { return s.Realm == realm }) {
+			continue
+		}
+		if err = adminClient.DeleteRealm(ctx, realm); err != nil {
+			return err
+		}
+	}
+
+	for _, snapshot := range snapshots {
+		if err = k.Restore(ctx, snapshot); err != nil {
+			return fmt.Errorf("restore realm %s: %w", snapshot.Realm, err)
+		}
+	}
+
+	if k.mailbox != nil {
+		k.mailbox.Clear()
+		if err = k.configureSMTPCapture(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// snapshotRealms takes a snapshot of every realm except master.
+func (k *KeycloakContainer) snapshotRealms(ctx context.Context) ([]*RealmSnapshot, error) {
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	realms, err := adminClient.GetRealms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []*RealmSnapshot
+	for _, realm := range realms {
+		if realm == masterRealm {
+			continue
+		}
+		snapshot, err := k.Snapshot(ctx, realm)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
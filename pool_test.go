@@ -0,0 +1,91 @@
+package keycloak
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestPool_Acquire(t *testing.T) {
+	ctx := context.Background()
+
+	pool, err := NewPool(ctx, 2,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("NewPool() error = %v", err)
+		return
+	}
+	t.Cleanup(func() {
+		if err := pool.Terminate(context.Background()); err != nil {
+			t.Errorf("Terminate() error = %v", err)
+		}
+	})
+
+	var first *KeycloakContainer
+	t.Run("mutate", func(t *testing.T) {
+		first = pool.Acquire(t)
+
+		adminClient, err := first.GetAdminClient(ctx)
+		if err != nil {
+			t.Errorf("GetAdminClient() error = %v", err)
+			return
+		}
+
+		err = adminClient.doRequest(ctx, http.MethodPost, "", map[string]interface{}{
+			"realm":   "Leaked",
+			"enabled": true,
+		}, nil)
+		if err != nil {
+			t.Errorf("create realm error = %v", err)
+			return
+		}
+
+		err = adminClient.doRequest(ctx, http.MethodDelete, "/"+realm+"/clients/"+mustClientID(t, adminClient), nil, nil)
+		if err != nil {
+			t.Errorf("delete client error = %v", err)
+		}
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		// both containers are available again, so acquire both to get the one mutated before
+		containers := []*KeycloakContainer{pool.Acquire(t), pool.Acquire(t)}
+		for _, k := range containers {
+			if k != first {
+				continue
+			}
+
+			adminClient, err := k.GetAdminClient(ctx)
+			if err != nil {
+				t.Errorf("GetAdminClient() error = %v", err)
+				return
+			}
+
+			realms, err := adminClient.GetRealms(ctx)
+			if err != nil {
+				t.Errorf("GetRealms() error = %v", err)
+				return
+			}
+			for _, r := range realms {
+				if r == "Leaked" {
+					t.Errorf("realm created by the previous test was not deleted")
+				}
+			}
+
+			if _, err = adminClient.GetClient(realm, client); err != nil {
+				t.Errorf("GetClient() error = %v", err)
+			}
+		}
+	})
+}
+
+func mustClientID(t *testing.T, adminClient *AdminClient) string {
+	t.Helper()
+
+	c, err := adminClient.GetClient(realm, client)
+	if err != nil {
+		t.Fatalf("GetClient() error = %v", err)
+	}
+	return *c.ID
+}
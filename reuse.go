@@ -0,0 +1,58 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// WithReuse is option to share KeycloakContainer between test processes, e.g. between the packages
+// of a go test ./... run, which each start their own process.
+// The first Run creates a container named after name and a hash of the image and the options,
+// later runs with the same name, image and options attach to it once it is verified to be healthy.
+// Runs with different options create their own container.
+// A shared container must not be terminated by the tests using it, it is removed when the test run ends.
+// WithReuse cannot be combined with options that call back to the test process,
+// like WithSMTPCapture or WithHostCallbacks, because they are bound to the process that created the container.
+func WithReuse(name string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if name == "" {
+			return errors.New("reuse name must be provided")
+		}
+		req.Name = name
+		req.Reuse = true
+
+		return nil
+	}
+}
+
+// prepareReuse names the container after the fingerprint of the request,
+// so only containers started with the same image and options are reused.
+func prepareReuse(req *testcontainers.GenericContainerRequest) error {
+	if req.Env[keycloakHostCallbackPortsEnv] != "" {
+		return errors.New("WithReuse cannot be combined with host callbacks")
+	}
+
+	fingerprint, err := requestFingerprint(req)
+	if err != nil {
+		return err
+	}
+	req.Name = req.Name + "-" + fingerprint[:12]
+
+	return nil
+}
+
+// checkHealth verifies that the admin API of KeycloakContainer is usable.
+func (k *KeycloakContainer) checkHealth(ctx context.Context) error {
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err = adminClient.GetRealms(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package keycloak
+
+import (
+	"context"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestPrepareReuse(t *testing.T) {
+	name := func(opts ...testcontainers.CustomizeRequestOption) (string, error) {
+		req := testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image: "keycloak/keycloak:26.0",
+				Env:   map[string]string{},
+				Cmd:   []string{keycloakStartupCommand},
+			},
+		}
+		for _, opt := range append([]testcontainers.CustomizeRequestOption{WithReuse("keycloak")}, opts...) {
+			if err := opt(&req); err != nil {
+				return "", err
+			}
+		}
+		// Run prepares the reuse of the rendered configuration
+		c, err := requestConfig(&req)
+		if err != nil {
+			return "", err
+		}
+		if err = applyConfig(&req, c); err != nil {
+			return "", err
+		}
+		err = prepareReuse(&req)
+		return req.Name, err
+	}
+
+	base, err := name()
+	if err != nil {
+		t.Errorf("prepareReuse() error = %v", err)
+		return
+	}
+
+	if got, _ := name(); got != base {
+		t.Errorf("prepareReuse() name = %s, want %s", got, base)
+	}
+	if got, _ := name(WithLogLevels(LogLevelDebug, nil)); got != base {
+		t.Errorf("prepareReuse() name depends on log levels")
+	}
+	if got, _ := name(WithContextPath("/auth")); got == base {
+		t.Errorf("prepareReuse() name does not depend on options")
+	}
+	themes, err := name(WithThemes("testdata/themes"))
+	if err != nil {
+		t.Errorf("prepareReuse() with themes error = %v", err)
+		return
+	}
+	if got, _ := name(WithThemes(t.TempDir())); got == themes {
+		t.Errorf("prepareReuse() name does not depend on the themes directory")
+	}
+	if _, err = name(WithHostCallbacks(12345)); err == nil {
+		t.Errorf("prepareReuse() with host callbacks error = nil")
+	}
+}
+
+func TestKeycloakContainer_WithReuse(t *testing.T) {
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		container, err := Run(ctx,
+			"keycloak/keycloak:26.0",
+			WithRealmImportFile("testdata/realm-export.json"),
+			WithReuse("testcontainers-keycloak-reuse"),
+		)
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+			return
+		}
+
+		ids = append(ids, container.GetContainerID())
+		if i == 0 {
+			testcontainers.CleanupContainer(t, container)
+		}
+	}
+
+	if ids[0] != ids[1] {
+		t.Errorf("Run() started container %s, want reused %s", ids[1], ids[0])
+	}
+}
@@ -0,0 +1,55 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// ServerInfo represents information about the Keycloak server(https://www.keycloak.org/docs-api/latest/rest-api/index.html#ServerInfoRepresentation).
+type ServerInfo struct {
+	SystemInfo *SystemInfo         `json:"systemInfo,omitempty"`
+	Providers  *map[string]SPIInfo `json:"providers,omitempty"`
+}
+
+// SystemInfo represents the system information of the Keycloak server(https://www.keycloak.org/docs-api/latest/rest-api/index.html#SystemInfoRepresentation).
+type SystemInfo struct {
+	Version     *string `json:"version,omitempty"`
+	ServerTime  *string `json:"serverTime,omitempty"`
+	Uptime      *string `json:"uptime,omitempty"`
+	JavaVersion *string `json:"javaVersion,omitempty"`
+}
+
+// SPIInfo represents a service provider interface and its provider implementations(https://www.keycloak.org/docs-api/latest/rest-api/index.html#SpiInfoRepresentation).
+type SPIInfo struct {
+	Internal  *bool                    `json:"internal,omitempty"`
+	Providers *map[string]ProviderInfo `json:"providers,omitempty"`
+}
+
+// ProviderInfo represents a provider implementation of an SPI(https://www.keycloak.org/docs-api/latest/rest-api/index.html#ProviderRepresentation).
+type ProviderInfo struct {
+	Order           *int               `json:"order,omitempty"`
+	OperationalInfo *map[string]string `json:"operationalInfo,omitempty"`
+}
+
+// GetServerInfo returns information about the Keycloak server, e.g. its version and the registered providers.
+func (a *AdminClient) GetServerInfo(ctx context.Context) (*ServerInfo, error) {
+	var info ServerInfo
+	if _, err := a.sendURL(ctx, http.MethodGet, a.serverInfoURL(), nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// getRawServerInfo returns the complete server info document.
+func (a *AdminClient) getRawServerInfo(ctx context.Context) (json.RawMessage, error) {
+	var info json.RawMessage
+	if _, err := a.sendURL(ctx, http.MethodGet, a.serverInfoURL(), nil, &info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (a *AdminClient) serverInfoURL() string {
+	return a.ServerURL + "/admin/serverinfo"
+}
@@ -0,0 +1,136 @@
+package keycloak
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// UserSession represents a Keycloak user session(https://www.keycloak.org/docs-api/latest/rest-api/index.html#UserSessionRepresentation).
+type UserSession struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	UserID        string `json:"userId"`
+	IPAddress     string `json:"ipAddress"`
+	Start         int64  `json:"start"`
+	LastAccess    int64  `json:"lastAccess"`
+	RememberMe    bool   `json:"rememberMe"`
+	TransientUser bool   `json:"transientUser"`
+	// Clients maps the internal ids of the clients of the session to their clientId.
+	Clients map[string]string `json:"clients"`
+}
+
+// GlobalRequestResult is the result of a request Keycloak forwards to the admin URLs of clients,
+// e.g. pushing a revocation policy.
+type GlobalRequestResult struct {
+	SuccessRequests []string `json:"successRequests"`
+	FailedRequests  []string `json:"failedRequests"`
+}
+
+// GetUserSessions returns the sessions of the user with the given id.
+func (a *AdminClient) GetUserSessions(ctx context.Context, realm, userID string) ([]UserSession, error) {
+	var sessions []UserSession
+	if err := a.doRequest(ctx, http.MethodGet, userPath(realm, userID)+"/sessions", nil, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// GetUserOfflineSessions returns the offline sessions of the user with the given id for the client with the given clientID.
+func (a *AdminClient) GetUserOfflineSessions(ctx context.Context, realm, userID, clientID string) ([]UserSession, error) {
+	c, err := a.GetClient(realm, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []UserSession
+	if err = a.doRequest(ctx, http.MethodGet, userPath(realm, userID)+"/offline-sessions/"+url.PathEscape(*c.ID), nil, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// GetClientSessions returns the user sessions of the client with the given clientID.
+func (a *AdminClient) GetClientSessions(ctx context.Context, realm, clientID string) ([]UserSession, error) {
+	return a.clientSessions(ctx, realm, clientID, "/user-sessions")
+}
+
+// GetClientOfflineSessions returns the offline user sessions of the client with the given clientID.
+func (a *AdminClient) GetClientOfflineSessions(ctx context.Context, realm, clientID string) ([]UserSession, error) {
+	return a.clientSessions(ctx, realm, clientID, "/offline-sessions")
+}
+
+// LogoutUser removes all sessions of the user with the given id.
+func (a *AdminClient) LogoutUser(ctx context.Context, realm, userID string) error {
+	return a.doRequest(ctx, http.MethodPost, userPath(realm, userID)+"/logout", nil, nil)
+}
+
+// LogoutAllSessions removes all user sessions of the realm.
+func (a *AdminClient) LogoutAllSessions(ctx context.Context, realm string) (*GlobalRequestResult, error) {
+	var result GlobalRequestResult
+	if err := a.doRequest(ctx, http.MethodPost, "/"+url.PathEscape(realm)+"/logout-all", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteSession removes the user session with the given id.
+func (a *AdminClient) DeleteSession(ctx context.Context, realm, sessionID string) error {
+	return a.doRequest(ctx, http.MethodDelete, "/"+url.PathEscape(realm)+"/sessions/"+url.PathEscape(sessionID), nil, nil)
+}
+
+// PushRealmRevocation sets the not-before policy of the realm to now, so all tokens issued before are rejected,
+// and pushes the policy to the admin URLs of the clients of the realm.
+func (a *AdminClient) PushRealmRevocation(ctx context.Context, realm string) (*GlobalRequestResult, error) {
+	err := a.doRequest(ctx, http.MethodPut, "/"+url.PathEscape(realm), map[string]int64{
+		"notBefore": time.Now().Unix(),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result GlobalRequestResult
+	if err = a.doRequest(ctx, http.MethodPost, "/"+url.PathEscape(realm)+"/push-revocation", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PushClientRevocation sets the not-before policy of the client with the given clientID to now,
+// so all tokens issued for it before are rejected, and pushes the policy to the admin URL of the client.
+func (a *AdminClient) PushClientRevocation(ctx context.Context, realm, clientID string) (*GlobalRequestResult, error) {
+	c, err := a.GetClient(realm, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	notBefore := int32(time.Now().Unix())
+	c.NotBefore = &notBefore
+	if err = a.UpdateClient(ctx, realm, *c); err != nil {
+		return nil, err
+	}
+
+	var result GlobalRequestResult
+	if err = a.doRequest(ctx, http.MethodPost, "/"+url.PathEscape(realm)+"/clients/"+url.PathEscape(*c.ID)+"/push-revocation", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (a *AdminClient) clientSessions(ctx context.Context, realm, clientID, kind string) ([]UserSession, error) {
+	path, err := a.clientPath(realm, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []UserSession
+	if err = a.doRequest(ctx, http.MethodGet, path+kind, nil, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func userPath(realm, userID string) string {
+	return "/" + url.PathEscape(realm) + "/users/" + url.PathEscape(userID)
+}
@@ -0,0 +1,118 @@
+package keycloak
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestAdminClient_Sessions(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	err = adminClient.doRequest(ctx, http.MethodPost, "/"+realm+"/users", map[string]interface{}{
+		"username":      "session-user",
+		"email":         "session-user@example.com",
+		"firstName":     "Session",
+		"lastName":      "User",
+		"enabled":       true,
+		"emailVerified": true,
+		"credentials": []map[string]interface{}{
+			{"type": "password", "value": "secret", "temporary": false},
+		},
+	}, nil)
+	if err != nil {
+		t.Errorf("create user error = %v", err)
+		return
+	}
+
+	var users []struct {
+		ID string `json:"id"`
+	}
+	if err = adminClient.doRequest(ctx, http.MethodGet, "/"+realm+"/users?username=session-user", nil, &users); err != nil || len(users) != 1 {
+		t.Errorf("get user error = %v", err)
+		return
+	}
+	userID := users[0].ID
+
+	cfg, err := container.GetOIDCConfig(ctx, realm, client)
+	if err != nil {
+		t.Errorf("GetOIDCConfig() error = %v", err)
+		return
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.PostForm(cfg.TokenURL, url.Values{
+			"grant_type":    {"password"},
+			"client_id":     {cfg.ClientID},
+			"client_secret": {cfg.ClientSecret},
+			"username":      {"session-user"},
+			"password":      {"secret"},
+		})
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Errorf("password grant error = %v", err)
+			return
+		}
+		resp.Body.Close()
+	}
+
+	sessions, err := adminClient.GetUserSessions(ctx, realm, userID)
+	if err != nil || len(sessions) != 2 || sessions[0].Username != "session-user" {
+		t.Errorf("GetUserSessions() = %v, error = %v", sessions, err)
+		return
+	}
+
+	clientSessions, err := adminClient.GetClientSessions(ctx, realm, client)
+	if err != nil || len(clientSessions) != 2 {
+		t.Errorf("GetClientSessions() = %v, error = %v", clientSessions, err)
+		return
+	}
+
+	if err = adminClient.DeleteSession(ctx, realm, sessions[0].ID); err != nil {
+		t.Errorf("DeleteSession() error = %v", err)
+		return
+	}
+
+	if sessions, err = adminClient.GetUserSessions(ctx, realm, userID); err != nil || len(sessions) != 1 {
+		t.Errorf("GetUserSessions() = %v, error = %v", sessions, err)
+		return
+	}
+
+	if err = adminClient.LogoutUser(ctx, realm, userID); err != nil {
+		t.Errorf("LogoutUser() error = %v", err)
+		return
+	}
+
+	if sessions, err = adminClient.GetUserSessions(ctx, realm, userID); err != nil || len(sessions) != 0 {
+		t.Errorf("GetUserSessions() = %v, error = %v", sessions, err)
+		return
+	}
+
+	if _, err = adminClient.PushRealmRevocation(ctx, realm); err != nil {
+		t.Errorf("PushRealmRevocation() error = %v", err)
+		return
+	}
+
+	if _, err = adminClient.PushClientRevocation(ctx, realm, client); err != nil {
+		t.Errorf("PushClientRevocation() error = %v", err)
+	}
+}
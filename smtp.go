@@ -0,0 +1,466 @@
+package keycloak
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	keycloakSMTPCaptureEnv = "KEYCLOAK_SMTP_CAPTURE"
+	smtpCaptureFrom        = "keycloak@testcontainers.local"
+	smtpCaptureDisplayName = "Keycloak"
+)
+
+var linkPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+
+// Email is a message captured by a Mailbox.
+type Email struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+	// Links contains the URLs found in the text and HTML bodies, e.g. action links
+	// of verify-email, reset-password and execute-actions emails.
+	Links []string
+	Raw   []byte
+}
+
+// Mailbox is a minimal SMTP server running in the test process that captures every email it receives.
+type Mailbox struct {
+	listener net.Listener
+
+	mu        sync.Mutex
+	emails    []*Email
+	consumed  map[*Email]bool
+	conns     map[net.Conn]struct{}
+	received  chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewMailbox starts a Mailbox listening on a random local port.
+func NewMailbox() (*Mailbox, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Mailbox{
+		listener: l,
+		consumed: make(map[*Email]bool),
+		conns:    make(map[net.Conn]struct{}),
+		received: make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+
+	m.wg.Add(1)
+	go m.serve()
+
+	return m, nil
+}
+
+// Port returns the port the Mailbox is listening on.
+func (m *Mailbox) Port() int {
+	return m.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Close stops the Mailbox.
+func (m *Mailbox) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.closed)
+		err = m.listener.Close()
+
+		m.mu.Lock()
+		for conn := range m.conns {
+			_ = conn.Close()
+		}
+		m.mu.Unlock()
+
+		m.wg.Wait()
+	})
+
+	return err
+}
+
+// Emails returns all emails received so far.
+func (m *Mailbox) Emails() []*Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Email(nil), m.emails...)
+}
+
+// Clear removes all received emails.
+func (m *Mailbox) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.emails = nil
+	m.consumed = make(map[*Email]bool)
+}
+
+// WaitForEmail blocks until an email addressed to the given recipient is received, or ctx is done.
+// Every email is returned only once, so consecutive calls return consecutive emails.
+func (m *Mailbox) WaitForEmail(ctx context.Context, to string) (*Email, error) {
+	for {
+		m.mu.Lock()
+		received := m.received
+		for _, e := range m.emails {
+			if !m.consumed[e] && hasRecipient(e, to) {
+				m.consumed[e] = true
+				m.mu.Unlock()
+				return e, nil
+			}
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-received:
+		case <-m.closed:
+			return nil, errors.New("mailbox closed")
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no email for %s received: %w", to, ctx.Err())
+		}
+	}
+}
+
+// SMTPServer returns the smtpServer realm configuration pointing to the Mailbox
+// from inside KeycloakContainer.
+func (m *Mailbox) SMTPServer() map[string]string {
+	return map[string]string{
+		"host":            testcontainers.HostInternal,
+		"port":            strconv.Itoa(m.Port()),
+		"from":            smtpCaptureFrom,
+		"fromDisplayName": smtpCaptureDisplayName,
+		"auth":            "false",
+		"ssl":             "false",
+		"starttls":        "false",
+	}
+}
+
+func (m *Mailbox) serve() {
+	defer m.wg.Done()
+
+	for {
+		conn, err := m.listener.Accept()
+		if err != nil {
+			return
+		}
+		m.mu.Lock()
+		m.conns[conn] = struct{}{}
+		m.mu.Unlock()
+
+		select {
+		case <-m.closed:
+			_ = conn.Close()
+		default:
+		}
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.handle(conn)
+
+			m.mu.Lock()
+			delete(m.conns, conn)
+			m.mu.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+func (m *Mailbox) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(w, format+"\r\n", args...)
+		w.Flush()
+	}
+
+	var from string
+	var to []string
+
+	reply("220 testcontainers-keycloak SMTP capture")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "HELO":
+			reply("250 Hello")
+		case "EHLO":
+			reply("250-Hello")
+			reply("250 8BITMIME")
+		case "MAIL":
+			from = smtpAddress(line)
+			to = nil
+			reply("250 OK")
+		case "RCPT":
+			to = append(to, smtpAddress(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readSMTPData(r)
+			if err != nil {
+				return
+			}
+			m.store(from, to, data)
+			reply("250 OK")
+		case "RSET":
+			from, to = "", nil
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (m *Mailbox) store(from string, to []string, data []byte) {
+	e := parseEmail(data)
+	e.From = from
+	e.To = to
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.emails = append(m.emails, e)
+	close(m.received)
+	m.received = make(chan struct{})
+}
+
+// WithSMTPCapture is option to start a Mailbox and configure the smtpServer of every realm
+// of KeycloakContainer to send emails to it. The Mailbox is started by Run, is available via
+// KeycloakContainer.Mailbox and is stopped when the container is terminated.
+func WithSMTPCapture() testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		req.Env[keycloakSMTPCaptureEnv] = "true"
+
+		return nil
+	}
+}
+
+// startSMTPCapture starts the Mailbox of WithSMTPCapture and makes it reachable from the container.
+// The Mailbox is stopped when the container is terminated.
+func startSMTPCapture(req *testcontainers.GenericContainerRequest) (*Mailbox, error) {
+	mailbox, err := NewMailbox()
+	if err != nil {
+		return nil, err
+	}
+	if err = WithHostCallbacks(mailbox.Port())(req); err != nil {
+		_ = mailbox.Close()
+		return nil, err
+	}
+
+	req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+		PostTerminates: []testcontainers.ContainerHook{
+			func(ctx context.Context, c testcontainers.Container) error {
+				return mailbox.Close()
+			},
+		},
+	})
+
+	return mailbox, nil
+}
+
+// Mailbox returns the Mailbox started by WithSMTPCapture, or nil if the option was not used.
+func (k *KeycloakContainer) Mailbox() *Mailbox {
+	return k.mailbox
+}
+
+// ConfigureSMTPCapture points the smtpServer of the realm to the Mailbox started by WithSMTPCapture.
+// Realms existing at startup are configured automatically, this is needed for realms created later.
+func (k *KeycloakContainer) ConfigureSMTPCapture(ctx context.Context, realm string) error {
+	if k.mailbox == nil {
+		return errors.New("no mailbox configured, use WithSMTPCapture")
+	}
+
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return adminClient.SetSMTPServer(ctx, realm, k.mailbox.SMTPServer())
+}
+
+func (k *KeycloakContainer) configureSMTPCapture(ctx context.Context) error {
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	realms, err := adminClient.GetRealms(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, realm := range realms {
+		if err = adminClient.SetSMTPServer(ctx, realm, k.mailbox.SMTPServer()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRealms returns the names of all realms.
+func (a *AdminClient) GetRealms(ctx context.Context) ([]string, error) {
+	var realms []struct {
+		Realm string `json:"realm"`
+	}
+	if err := a.doRequest(ctx, http.MethodGet, "", nil, &realms); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(realms))
+	for _, r := range realms {
+		names = append(names, r.Realm)
+	}
+
+	return names, nil
+}
+
+// SetSMTPServer updates the smtpServer configuration of the realm.
+// See https://www.keycloak.org/docs/latest/server_admin/#_email
+func (a *AdminClient) SetSMTPServer(ctx context.Context, realm string, smtpServer map[string]string) error {
+	return a.doRequest(ctx, http.MethodPut, "/"+url.PathEscape(realm), map[string]interface{}{
+		"smtpServer": smtpServer,
+	}, nil)
+}
+
+func hasRecipient(e *Email, to string) bool {
+	for _, rcpt := range e.To {
+		if strings.EqualFold(rcpt, to) {
+			return true
+		}
+	}
+	return false
+}
+
+func smtpAddress(line string) string {
+	start := strings.Index(line, "<")
+	end := strings.LastIndex(line, ">")
+	if start == -1 || end < start {
+		if i := strings.Index(line, ":"); i != -1 {
+			return strings.TrimSpace(line[i+1:])
+		}
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func readSMTPData(r *bufio.Reader) ([]byte, error) {
+	var data bytes.Buffer
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if line == ".\r\n" || line == ".\n" {
+			return data.Bytes(), nil
+		}
+		// dot-stuffing, see RFC 5321 section 4.5.2
+		data.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+func parseEmail(data []byte) *Email {
+	e := &Email{Raw: data}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		e.Text = string(data)
+		e.Links = findLinks(e.Text)
+		return e
+	}
+
+	e.Subject = msg.Header.Get("Subject")
+	if subject, err := new(mime.WordDecoder).DecodeHeader(e.Subject); err == nil {
+		e.Subject = subject
+	}
+
+	parsePart(e, msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	e.Links = findLinks(e.Text + "\n" + html.UnescapeString(e.HTML))
+
+	return e
+}
+
+func parsePart(e *Email, contentType, encoding string, body io.Reader) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextRawPart()
+			if err != nil {
+				return
+			}
+			parsePart(e, p.Header.Get("Content-Type"), p.Header.Get("Content-Transfer-Encoding"), p)
+		}
+	}
+
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return
+	}
+
+	switch mediaType {
+	case "text/html":
+		e.HTML += string(content)
+	case "text/plain":
+		e.Text += string(content)
+	}
+}
+
+func findLinks(s string) []string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, link := range linkPattern.FindAllString(s, -1) {
+		link = strings.TrimRight(link, ".,;)")
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
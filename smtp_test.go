@@ -0,0 +1,157 @@
+package keycloak
+
+import (
+	"context"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestMailbox_WaitForEmail(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mailbox, err := NewMailbox()
+	if err != nil {
+		t.Errorf("NewMailbox() error = %v", err)
+		return
+	}
+	defer mailbox.Close()
+
+	msg := strings.Join([]string{
+		"From: keycloak@example.com",
+		"To: user@example.com",
+		"Subject: =?UTF-8?Q?Update_Your_Account?=",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/alternative; boundary="b"`,
+		"",
+		"--b",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Click http://localhost:8080/realms/Test/login-actions/action-token?key=3Dabc&client_id=3Dtest",
+		"--b",
+		"Content-Type: text/html; charset=UTF-8",
+		"Content-Transfer-Encoding: base64",
+		"",
+		"PGEgaHJlZj0iaHR0cDovL2xvY2FsaG9zdDo4MDgwL3JlYWxtcy9UZXN0L2xvZ2luLWFjdGlvbnMv",
+		"YWN0aW9uLXRva2VuP2tleT1hYmMmYW1wO2NsaWVudF9pZD10ZXN0Ij5MaW5rPC9hPg==",
+		"--b--",
+		"",
+	}, "\r\n")
+
+	go func() {
+		err := smtp.SendMail("127.0.0.1:"+strconv.Itoa(mailbox.Port()), nil,
+			"keycloak@example.com", []string{"user@example.com"}, []byte(msg))
+		if err != nil {
+			t.Errorf("SendMail() error = %v", err)
+		}
+	}()
+
+	email, err := mailbox.WaitForEmail(ctx, "user@example.com")
+	if err != nil {
+		t.Errorf("WaitForEmail() error = %v", err)
+		return
+	}
+
+	if email.Subject != "Update Your Account" {
+		t.Errorf("WaitForEmail() subject = %v", email.Subject)
+	}
+
+	want := "http://localhost:8080/realms/Test/login-actions/action-token?key=abc&client_id=test"
+	if len(email.Links) != 1 || email.Links[0] != want {
+		t.Errorf("WaitForEmail() links = %v, want %v", email.Links, want)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err = mailbox.WaitForEmail(ctx, "user@example.com"); err == nil {
+		t.Errorf("WaitForEmail() returned the same email twice")
+	}
+}
+
+func TestStartSMTPCapture(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{Env: map[string]string{}},
+	}
+	mailbox, err := startSMTPCapture(req)
+	if err != nil {
+		t.Errorf("startSMTPCapture() error = %v", err)
+		return
+	}
+	defer mailbox.Close()
+
+	if req.Env[keycloakHostCallbackPortsEnv] != strconv.Itoa(mailbox.Port()) {
+		t.Errorf("Env[%s] = %v, want %v", keycloakHostCallbackPortsEnv, req.Env[keycloakHostCallbackPortsEnv], mailbox.Port())
+	}
+	if len(req.LifecycleHooks) != 1 || len(req.LifecycleHooks[0].PostTerminates) != 1 {
+		t.Errorf("LifecycleHooks = %v, want a hook that stops the Mailbox", req.LifecycleHooks)
+	}
+}
+
+func TestKeycloakContainer_WithSMTPCapture(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+		WithSMTPCapture(),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	err = adminClient.doRequest(ctx, http.MethodPost, "/"+realm+"/users", map[string]interface{}{
+		"username": "mail-user",
+		"email":    "mail-user@example.com",
+		"enabled":  true,
+	}, nil)
+	if err != nil {
+		t.Errorf("create user error = %v", err)
+		return
+	}
+
+	var users []struct {
+		ID string `json:"id"`
+	}
+	if err = adminClient.doRequest(ctx, http.MethodGet, "/"+realm+"/users?username=mail-user", nil, &users); err != nil || len(users) != 1 {
+		t.Errorf("get user error = %v", err)
+		return
+	}
+
+	err = adminClient.doRequest(ctx, http.MethodPut,
+		"/"+realm+"/users/"+users[0].ID+"/execute-actions-email?client_id="+url.QueryEscape(client)+"&redirect_uri="+url.QueryEscape("http://localhost/"),
+		[]string{"UPDATE_PASSWORD"}, nil)
+	if err != nil {
+		t.Errorf("execute-actions-email error = %v", err)
+		return
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	email, err := container.Mailbox().WaitForEmail(waitCtx, "mail-user@example.com")
+	if err != nil {
+		t.Errorf("WaitForEmail() error = %v", err)
+		return
+	}
+
+	if len(email.Links) == 0 {
+		t.Errorf("WaitForEmail() no links in %s", email.Raw)
+	}
+}
@@ -0,0 +1,135 @@
+package keycloak
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestKeycloakContainer_Restore(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	err = adminClient.doRequest(ctx, http.MethodPost, "/"+realm+"/users", map[string]interface{}{
+		"username":      "snapshot-user",
+		"email":         "snapshot-user@example.com",
+		"firstName":     "Snapshot",
+		"lastName":      "User",
+		"enabled":       true,
+		"emailVerified": true,
+		"credentials": []map[string]interface{}{
+			{"type": "password", "value": "secret", "temporary": false},
+		},
+	}, nil)
+	if err != nil {
+		t.Errorf("create user error = %v", err)
+		return
+	}
+
+	snapshot, err := container.Snapshot(ctx, realm)
+	if err != nil {
+		t.Errorf("Snapshot() error = %v", err)
+		return
+	}
+
+	if _, err = adminClient.PartialImport(ctx, realm, []byte(`{"clients": [{"clientId": "leaked-app"}]}`), PartialImportFail); err != nil {
+		t.Errorf("PartialImport() error = %v", err)
+		return
+	}
+
+	if err = container.Restore(ctx, snapshot); err != nil {
+		t.Errorf("Restore() error = %v", err)
+		return
+	}
+
+	if _, err = adminClient.GetClient(realm, "leaked-app"); err == nil {
+		t.Errorf("GetClient() found client created after the snapshot")
+		return
+	}
+
+	cfg, err := container.GetOIDCConfig(ctx, realm, client)
+	if err != nil {
+		t.Errorf("GetOIDCConfig() error = %v", err)
+		return
+	}
+
+	resp, err := http.PostForm(cfg.TokenURL, url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"username":      {"snapshot-user"},
+		"password":      {"secret"},
+	})
+	if err != nil {
+		t.Errorf("http.PostForm() error = %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("password grant after Restore() status = %v", resp.StatusCode)
+	}
+}
+
+func BenchmarkKeycloakContainer_Restore(b *testing.B) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		b.Fatalf("Run() error = %v", err)
+	}
+
+	testcontainers.CleanupContainer(b, container)
+
+	snapshot, err := container.Snapshot(ctx, realm)
+	if err != nil {
+		b.Fatalf("Snapshot() error = %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err = container.Restore(ctx, snapshot); err != nil {
+			b.Fatalf("Restore() error = %v", err)
+		}
+	}
+}
+
+func BenchmarkKeycloakContainer_Restart(b *testing.B) {
+	ctx := context.Background()
+
+	for i := 0; i < b.N; i++ {
+		container, err := Run(ctx,
+			"keycloak/keycloak:26.0",
+			WithRealmImportFile("testdata/realm-export.json"),
+		)
+		if err != nil {
+			b.Fatalf("Run() error = %v", err)
+		}
+
+		if err = container.Terminate(ctx); err != nil {
+			b.Fatalf("Terminate() error = %v", err)
+		}
+	}
+}
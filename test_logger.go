@@ -0,0 +1,80 @@
+package keycloak
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// WithTestLogger is option to forward the output of KeycloakContainer to t.Log.
+// Only entries logged at minLevel or above are forwarded, together with their stack traces.
+// Lines not written by the Keycloak logger are treated as INFO, unless they start with ERROR:.
+// Forwarding stops when the test and its cleanups have finished.
+func WithTestLogger(t testing.TB, minLevel LogLevel) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if err := validateLogLevel(minLevel); err != nil {
+			return err
+		}
+
+		l := &testLogger{t: t, minLevel: minLevel}
+		t.Cleanup(l.stop)
+
+		var logConsumerCfg testcontainers.LogConsumerConfig
+		if req.LogConsumerCfg != nil {
+			logConsumerCfg = *req.LogConsumerCfg
+		}
+		logConsumerCfg.Consumers = append(slices.Clone(logConsumerCfg.Consumers), l)
+		req.LogConsumerCfg = &logConsumerCfg
+
+		return nil
+	}
+}
+
+type testLogger struct {
+	t        testing.TB
+	minLevel LogLevel
+
+	mu      sync.Mutex
+	stopped bool
+	partial string
+	// current holds the entry the last line belonged to, so stack trace lines are filtered with it
+	current []LogEntry
+}
+
+func (l *testLogger) Accept(log testcontainers.Log) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stopped {
+		return
+	}
+
+	lines := strings.Split(l.partial+string(log.Content), "\n")
+	l.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// keep only the last entry, it is all that is needed to tell entries from stack trace lines
+		if len(l.current) > 1 {
+			l.current = l.current[len(l.current)-1:]
+		}
+		l.current = appendLogLine(l.current, line)
+
+		if l.minLevel.Enables(l.current[len(l.current)-1].Level) {
+			l.t.Log(line)
+		}
+	}
+}
+
+func (l *testLogger) stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopped = true
+}
@@ -0,0 +1,140 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// recordingTB is a testing.TB that records what is logged and whose result is decided by the test,
+// with cleanups that run on demand.
+type recordingTB struct {
+	testing.TB
+	failed   bool
+	lines    []string
+	cleanups []func()
+}
+
+func (r *recordingTB) Log(args ...interface{}) {
+	r.lines = append(r.lines, fmt.Sprint(args...))
+}
+
+func (r *recordingTB) Logf(format string, args ...interface{}) {
+	r.lines = append(r.lines, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recordingTB) Failed() bool {
+	return r.failed
+}
+
+// runCleanups runs the registered cleanups in reverse order like testing.T does.
+func (r *recordingTB) runCleanups() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+	r.cleanups = nil
+}
+
+func TestWithTestLogger(t *testing.T) {
+	tb := &recordingTB{TB: t}
+
+	req := testcontainers.GenericContainerRequest{}
+	if err := WithTestLogger(tb, LogLevelWarn)(&req); err != nil {
+		t.Errorf("WithTestLogger() error = %v", err)
+		return
+	}
+
+	consumer := req.LogConsumerCfg.Consumers[0]
+	consumer.Accept(testcontainers.Log{LogType: testcontainers.StdoutLog, Content: []byte(testLog)})
+
+	// WARN line, ERROR line with 5 stack trace lines and 2 ERROR: lines
+	if len(tb.lines) != 9 {
+		t.Errorf("logged %d lines, want 9: %q", len(tb.lines), tb.lines)
+	}
+
+	for _, cleanup := range tb.cleanups {
+		cleanup()
+	}
+	consumer.Accept(testcontainers.Log{LogType: testcontainers.StdoutLog, Content: []byte("ERROR: after the test\n")})
+	if len(tb.lines) != 9 {
+		t.Errorf("logged after the test finished: %q", tb.lines[len(tb.lines)-1])
+	}
+}
+
+func TestWithLogLevels(t *testing.T) {
+	tests := []struct {
+		name       string
+		root       LogLevel
+		categories map[string]LogLevel
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name: "root only",
+			root: LogLevelDebug,
+			want: []string{keycloakStartupCommand, "--log-level=debug"},
+		},
+		{
+			name: "categories",
+			root: LogLevelWarn,
+			categories: map[string]LogLevel{
+				"org.keycloak.events":      LogLevelDebug,
+				"org.hibernate.SQL":        LogLevelTrace,
+				"org.keycloak.broker.oidc": LogLevelInfo,
+			},
+			want: []string{keycloakStartupCommand, "--log-level=warn,org.hibernate.SQL:trace,org.keycloak.broker.oidc:info,org.keycloak.events:debug"},
+		},
+		{
+			name:       "unknown level",
+			categories: map[string]LogLevel{"org.keycloak.events": "VERBOSE"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testcontainers.GenericContainerRequest{}
+			err := WithLogLevels(tt.root, tt.categories)(&req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WithLogLevels() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			c, err := requestConfig(&req)
+			if err != nil {
+				t.Errorf("requestConfig() error = %v", err)
+				return
+			}
+			if got := c.CommandLine(); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("CommandLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeycloakContainer_WithTestLogger(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithTestLogger(t, LogLevelInfo),
+		WithLogLevels(LogLevelInfo, map[string]LogLevel{"org.keycloak.events": LogLevelDebug}),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	if len(container.LogEntries()) == 0 {
+		t.Errorf("LogEntries() is empty")
+	}
+}
@@ -0,0 +1,116 @@
+package keycloak
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	defaultThemes     = "/opt/keycloak/themes"
+	keycloakThemesEnv = "KEYCLOAK_THEMES"
+)
+
+// WithThemes is option to mount a directory of themes into /opt/keycloak/themes of KeycloakContainer.
+// The directory is bind mounted and theme caching is disabled, so changes to the themes
+// are rendered by the next request without restarting the container.
+// Each subdirectory is a theme, e.g. mytheme/login/theme.properties.
+// See https://www.keycloak.org/ui-customization/themes
+func WithThemes(themesDir string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		dir, err := filepath.Abs(themesDir)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("themes %s is not a directory", themesDir)
+		}
+
+		// the bind mount is not part of the request otherwise, so the fingerprint of WithReuse would miss it
+		req.Env[keycloakThemesEnv] = dir
+
+		modifier := req.HostConfigModifier
+		req.HostConfigModifier = func(hostConfig *container.HostConfig) {
+			if modifier != nil {
+				modifier(hostConfig)
+			}
+			hostConfig.Binds = append(hostConfig.Binds, dir+":"+defaultThemes+":ro")
+		}
+
+		for _, option := range [][2]string{
+			{"spi-theme-static-max-age", "-1"},
+			{"spi-theme-cache-themes", "false"},
+			{"spi-theme-cache-templates", "false"},
+		} {
+			if err := setOption(req, option[0], option[1]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// GetLoginPage returns the HTML of the login page of the realm, as rendered for the client
+// with the given clientID when it starts the authorization code flow.
+// The client needs a redirect URI that is not a wildcard pattern, see GetOIDCConfig.
+func (k *KeycloakContainer) GetLoginPage(ctx context.Context, realm, clientID string) (string, error) {
+	cfg, err := k.GetOIDCConfig(ctx, realm, clientID)
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.RedirectURL == "" {
+		return "", fmt.Errorf("client %s has no redirect URI to start the authorization code flow with", clientID)
+	}
+
+	authURL := cfg.AuthURL + "?" + url.Values{
+		"client_id":     {cfg.ClientID},
+		"redirect_uri":  {cfg.RedirectURL},
+		"response_type": {"code"},
+		"scope":         {oidcDefaultScope},
+	}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, authURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	page, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: unexpected status %d", cfg.AuthURL, resp.StatusCode)
+	}
+
+	return string(page), nil
+}
+
+// SetLoginTheme sets the theme of the login pages of the realm.
+func (a *AdminClient) SetLoginTheme(ctx context.Context, realm, theme string) error {
+	return a.doRequest(ctx, http.MethodPut, "/"+url.PathEscape(realm), map[string]interface{}{
+		"loginTheme": theme,
+	}, nil)
+}
@@ -0,0 +1,127 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestWithThemes(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Env: map[string]string{},
+			HostConfigModifier: func(hostConfig *container.HostConfig) {
+				hostConfig.Binds = append(hostConfig.Binds, "/tmp/data:/data")
+			},
+		},
+	}
+	if err := WithThemes("testdata/themes")(req); err != nil {
+		t.Errorf("WithThemes() error = %v", err)
+		return
+	}
+
+	var hostConfig container.HostConfig
+	req.HostConfigModifier(&hostConfig)
+	dir, err := filepath.Abs("testdata/themes")
+	if err != nil {
+		t.Errorf("Abs() error = %v", err)
+		return
+	}
+	if want := fmt.Sprint([]string{"/tmp/data:/data", dir + ":" + defaultThemes + ":ro"}); fmt.Sprint(hostConfig.Binds) != want {
+		t.Errorf("Binds = %v, want %v", hostConfig.Binds, want)
+	}
+
+	c, err := requestConfig(req)
+	if err != nil {
+		t.Errorf("requestConfig() error = %v", err)
+		return
+	}
+	want := "[start-dev --spi-theme-static-max-age=-1 --spi-theme-cache-themes=false --spi-theme-cache-templates=false]"
+	if got := c.CommandLine(); fmt.Sprint(got) != want {
+		t.Errorf("CommandLine() = %v, want %v", got, want)
+	}
+
+	if err = WithThemes("testdata/realm-export.json")(req); err == nil {
+		t.Errorf("WithThemes() with a file error = nil")
+	}
+}
+
+func TestKeycloakContainer_WithThemes(t *testing.T) {
+	ctx := context.Background()
+
+	// a copy of the themes, which is changed while the container runs
+	themes := t.TempDir()
+	if err := os.CopyFS(themes, os.DirFS("testdata/themes")); err != nil {
+		t.Errorf("CopyFS() error = %v", err)
+		return
+	}
+	// the keycloak user of the container has to be able to read the bind mounted themes
+	if err := os.Chmod(themes, 0o755); err != nil {
+		t.Errorf("Chmod() error = %v", err)
+		return
+	}
+
+	container, err := Run(ctx,
+		"keycloak/keycloak:26.0",
+		WithThemes(themes),
+		WithRealmImportFile("testdata/realm-export.json"),
+	)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+		return
+	}
+
+	testcontainers.CleanupContainer(t, container)
+
+	adminClient, err := container.GetAdminClient(ctx)
+	if err != nil {
+		t.Errorf("GetAdminClient() error = %v", err)
+		return
+	}
+
+	if err = adminClient.SetLoginTheme(ctx, realm, "custom"); err != nil {
+		t.Errorf("SetLoginTheme() error = %v", err)
+		return
+	}
+
+	c, err := adminClient.GetClient(realm, client)
+	if err != nil {
+		t.Errorf("GetClient() error = %v", err)
+		return
+	}
+	c.RedirectURIs = &[]string{"http://localhost:3000/callback"}
+	if err = adminClient.UpdateClient(ctx, realm, *c); err != nil {
+		t.Errorf("UpdateClient() error = %v", err)
+		return
+	}
+
+	page, err := container.GetLoginPage(ctx, realm, client)
+	if err != nil {
+		t.Errorf("GetLoginPage() error = %v", err)
+		return
+	}
+	if !strings.Contains(page, "Sign in to the custom theme") {
+		t.Errorf("GetLoginPage() = %v, want the title of the custom theme", page)
+	}
+
+	messages := filepath.Join(themes, "custom", "login", "messages", "messages_en.properties")
+	if err = os.WriteFile(messages, []byte("loginAccountTitle=Sign in to the changed theme\n"), 0o644); err != nil {
+		t.Errorf("WriteFile() error = %v", err)
+		return
+	}
+
+	page, err = container.GetLoginPage(ctx, realm, client)
+	if err != nil {
+		t.Errorf("GetLoginPage() error = %v", err)
+		return
+	}
+	if !strings.Contains(page, "Sign in to the changed theme") {
+		t.Errorf("GetLoginPage() = %v, want the title of the changed theme", page)
+	}
+}
@@ -0,0 +1,200 @@
+package keycloak
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+var (
+	// hostnameV2Version is the first version with the hostname:v2 options, which replaced hostname-url and friends.
+	// See https://www.keycloak.org/docs/latest/upgrading/#new-hostname-options
+	hostnameV2Version = Version{Major: 25}
+	// bootstrapAdminVersion is the first version creating the initial admin from KC_BOOTSTRAP_ADMIN_* variables.
+	bootstrapAdminVersion = Version{Major: 26}
+	// quarkusVersion is the first version distributed on Quarkus, which this module supports.
+	quarkusVersion = Version{Major: 17}
+)
+
+// keycloakArgVersions lists the command line options that only exist in a range of versions.
+var keycloakArgVersions = map[string]versionRange{
+	"--hostname-url":                  {until: hostnameV2Version},
+	"--hostname-admin-url":            {until: hostnameV2Version},
+	"--hostname-path":                 {until: hostnameV2Version},
+	"--hostname-port":                 {until: hostnameV2Version},
+	"--hostname-strict-backchannel":   {until: hostnameV2Version},
+	"--hostname-backchannel-dynamic":  {since: hostnameV2Version},
+	"--hostname-admin":                {since: hostnameV2Version},
+	"--bootstrap-admin-client-id":     {since: bootstrapAdminVersion},
+	"--bootstrap-admin-client-secret": {since: bootstrapAdminVersion},
+	"--bootstrap-admin-username":      {since: bootstrapAdminVersion},
+	"--bootstrap-admin-password":      {since: bootstrapAdminVersion},
+}
+
+// Version is the version of a Keycloak server, e.g. 26.0.7.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses a Keycloak version like 26.0.7, 26.0 or 26.
+// Suffixes like -1 or .redhat-00001 are ignored.
+func ParseVersion(s string) (Version, error) {
+	var v Version
+
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(s, "-+"); i != -1 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		if i == len(numbers) {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			if i == 0 {
+				return v, fmt.Errorf("invalid version %q", s)
+			}
+			break
+		}
+		*numbers[i] = n
+	}
+
+	return v, nil
+}
+
+// String returns the version as major.minor.patch.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is lower than, equal to or greater than o.
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return cmp.Compare(v.Major, o.Major)
+	case v.Minor != o.Minor:
+		return cmp.Compare(v.Minor, o.Minor)
+	default:
+		return cmp.Compare(v.Patch, o.Patch)
+	}
+}
+
+// AtLeast reports whether v is o or a later version.
+func (v Version) AtLeast(o Version) bool {
+	return v.Compare(o) >= 0
+}
+
+// Version returns the version reported by the running Keycloak server.
+func (k *KeycloakContainer) Version(ctx context.Context) (Version, error) {
+	adminClient, err := k.GetAdminClient(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+
+	info, err := adminClient.GetServerInfo(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+	if info.SystemInfo == nil || info.SystemInfo.Version == nil {
+		return Version{}, errors.New("server info contains no version")
+	}
+
+	return ParseVersion(*info.SystemInfo.Version)
+}
+
+type versionRange struct {
+	since Version
+	until Version
+}
+
+func (r versionRange) contains(v Version) bool {
+	return v.AtLeast(r.since) && (r.until == Version{} || !v.AtLeast(r.until))
+}
+
+// keycloakImageRepository is the repository of the official Keycloak images, e.g. on quay.io.
+const keycloakImageRepository = "keycloak/keycloak"
+
+// keycloakImageRepositories are the repositories of the official Keycloak images, whose tags are versions.
+var keycloakImageRepositories = []string{keycloakImageRepository, legacyImageRepository}
+
+// imageVersion returns the Keycloak version of an image from its tag, e.g. 26.0 of keycloak/keycloak:26.0.
+// Only tags of the official repositories, also via a registry or mirror like quay.io/keycloak/keycloak, are versions.
+// Tags of other repositories, tags without version like latest or nightly and digests are reported as unknown.
+func imageVersion(img string) (Version, bool) {
+	repository, tag := splitImage(img)
+	if tag == "" || !slices.ContainsFunc(keycloakImageRepositories, func(r string) bool { return isRepository(repository, r) }) {
+		return Version{}, false
+	}
+
+	v, err := ParseVersion(tag)
+	if err != nil {
+		return Version{}, false
+	}
+	return v, true
+}
+
+// splitImage returns the repository and the tag of an image like quay.io/keycloak/keycloak:26.0@sha256:0123.
+func splitImage(img string) (string, string) {
+	repository, _, _ := strings.Cut(img, "@")
+	if i := strings.LastIndex(repository, ":"); i != -1 && !strings.Contains(repository[i:], "/") {
+		return repository[:i], repository[i+1:]
+	}
+	return repository, ""
+}
+
+// isRepository reports whether the repository is name, in any registry, e.g. quay.io/keycloak/keycloak is keycloak/keycloak.
+func isRepository(repository, name string) bool {
+	return repository == name || strings.HasSuffix(repository, "/"+name)
+}
+
+// applyVersion adapts the request to the Keycloak version of its image and fails
+// if an option is not supported by that version. Requests for images of unknown
+// version are configured for both older and newer versions where possible.
+func applyVersion(req *testcontainers.GenericContainerRequest, c *Config, v Version, known bool) error {
+	if !known {
+		return nil
+	}
+
+	if !v.AtLeast(quarkusVersion) {
+		return fmt.Errorf("keycloak %s is not supported, the minimum version is %s", v, quarkusVersion)
+	}
+
+	if v.AtLeast(bootstrapAdminVersion) {
+		delete(req.Env, keycloakAdminUsernameEnv)
+		delete(req.Env, keycloakAdminPasswordEnv)
+	} else {
+		delete(req.Env, keycloakAdminBootstrapUsernameEnv)
+		delete(req.Env, keycloakAdminBootstrapPasswordEnv)
+	}
+
+	for _, name := range c.Names() {
+		if r, ok := keycloakArgVersions["--"+name]; ok && !r.contains(v) {
+			return fmt.Errorf("option --%s is not supported by keycloak %s", name, v)
+		}
+	}
+
+	return nil
+}
+
+// setHostname sets the options pinning the frontend URL to hostname.
+// Versions before hostname:v2 keep using the request URL for back-channel requests by default.
+func setHostname(c *Config, hostname string, v Version, known bool) error {
+	if known && !v.AtLeast(hostnameV2Version) {
+		return c.Set("hostname-url", hostname)
+	}
+	if err := c.Set("hostname", hostname); err != nil {
+		return err
+	}
+	return c.Set("hostname-backchannel-dynamic", "true")
+}
@@ -0,0 +1,204 @@
+package keycloak
+
+import (
+	"context"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    Version
+		wantErr bool
+	}{
+		{version: "26.0.7", want: Version{Major: 26, Minor: 0, Patch: 7}},
+		{version: "26.0", want: Version{Major: 26}},
+		{version: "24", want: Version{Major: 24}},
+		{version: "24.0.5-1", want: Version{Major: 24, Patch: 5}},
+		{version: "22.0.10.redhat-00001", want: Version{Major: 22, Patch: 10}},
+		{version: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := ParseVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageVersion(t *testing.T) {
+	tests := []struct {
+		image     string
+		want      Version
+		wantKnown bool
+	}{
+		{image: "keycloak/keycloak:26.0", want: Version{Major: 26}, wantKnown: true},
+		{image: "quay.io/keycloak/keycloak:24.0.5", want: Version{Major: 24, Patch: 5}, wantKnown: true},
+		{image: "localhost:5000/keycloak/keycloak:25.0@sha256:0123", want: Version{Major: 25}, wantKnown: true},
+		{image: "localhost:5000/keycloak/keycloak"},
+		{image: "keycloak/keycloak:nightly"},
+		{image: "keycloak/keycloak"},
+		// custom images are versioned independently of Keycloak
+		{image: "myorg/keycloak:1.2.0"},
+		{image: "registry.example.com/acme-kc:3.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, known := imageVersion(tt.image)
+			if got != tt.want || known != tt.wantKnown {
+				t.Errorf("imageVersion() = %v, %v, want %v, %v", got, known, tt.want, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestApplyVersion(t *testing.T) {
+	newRequest := func(args ...string) *testcontainers.GenericContainerRequest {
+		return &testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Env: map[string]string{
+					keycloakAdminUsernameEnv:          defaultKeycloakAdminUsername,
+					keycloakAdminBootstrapUsernameEnv: defaultKeycloakAdminUsername,
+					keycloakAdminPasswordEnv:          defaultKeycloakAdminPassword,
+					keycloakAdminBootstrapPasswordEnv: defaultKeycloakAdminPassword,
+				},
+				Cmd: append([]string{keycloakStartupCommand}, args...),
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		version Version
+		known   bool
+		args    []string
+		wantEnv []string
+		wantErr bool
+	}{
+		{
+			name:    "26 uses bootstrap admin",
+			version: Version{Major: 26},
+			known:   true,
+			wantEnv: []string{keycloakAdminBootstrapUsernameEnv, keycloakAdminBootstrapPasswordEnv},
+		},
+		{
+			name:    "24 uses legacy admin",
+			version: Version{Major: 24},
+			known:   true,
+			wantEnv: []string{keycloakAdminUsernameEnv, keycloakAdminPasswordEnv},
+		},
+		{
+			name:    "unknown version keeps both",
+			wantEnv: []string{keycloakAdminUsernameEnv, keycloakAdminPasswordEnv, keycloakAdminBootstrapUsernameEnv, keycloakAdminBootstrapPasswordEnv},
+		},
+		{
+			name:    "hostname v1 option on 26",
+			version: Version{Major: 26},
+			known:   true,
+			args:    []string{"--hostname-url=http://keycloak:8080"},
+			wantErr: true,
+		},
+		{
+			name:    "hostname v2 option on 24",
+			version: Version{Major: 24},
+			known:   true,
+			args:    []string{"--hostname-backchannel-dynamic=true"},
+			wantErr: true,
+		},
+		{
+			name:    "WildFly based version",
+			version: Version{Major: 16},
+			known:   true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(tt.args...)
+			c, err := requestConfig(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = applyVersion(req, c, tt.version, tt.known)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("applyVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(req.Env) != len(tt.wantEnv) {
+				t.Errorf("Env = %v, want %v", req.Env, tt.wantEnv)
+			}
+			for _, key := range tt.wantEnv {
+				if _, ok := req.Env[key]; !ok {
+					t.Errorf("Env = %v, want %v", req.Env, tt.wantEnv)
+				}
+			}
+		})
+	}
+}
+
+func TestKeycloakContainer_Version(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		image string
+		want  int
+	}{
+		{
+			name:  "KeycloakV24",
+			image: "keycloak/keycloak:24.0",
+			want:  24,
+		},
+		{
+			name:  "KeycloakV26",
+			image: "keycloak/keycloak:26.0",
+			want:  26,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nw, err := network.New(ctx)
+			if err != nil {
+				t.Errorf("network.New() error = %v", err)
+				return
+			}
+			testcontainers.CleanupNetwork(t, nw)
+
+			// the pinned hostname exercises the hostname options of both versions
+			container, err := Run(ctx,
+				tt.image,
+				WithNetwork(nw.Name, "keycloak"),
+				WithHostname(""),
+				WithAdminUsername(username),
+				WithAdminPassword(password),
+			)
+			if err != nil {
+				t.Errorf("Run() error = %v", err)
+				return
+			}
+
+			testcontainers.CleanupContainer(t, container)
+
+			version, err := container.Version(ctx)
+			if err != nil {
+				t.Errorf("Version() error = %v", err)
+				return
+			}
+			if version.Major != tt.want {
+				t.Errorf("Version() = %v, want major %d", version, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	dockerclient "github.com/moby/moby/client"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	keycloakWarmStartEnv      = "KEYCLOAK_WARM_START"
+	warmStartRepository       = "testcontainers-keycloak-warm-start"
+	warmStartFingerprintLabel = "org.testcontainers.keycloak.warm-start"
+	importRealmArg            = "--import-realm"
+)
+
+// WithWarmStart is option to start KeycloakContainer from a local image with a fully started
+// and seeded Keycloak, so realm import and the first start of the dev-file store are skipped.
+// On the first run, KeycloakContainer is committed into an image tagged by a hash of the image,
+// the options and the contents of the copied files, e.g. realm import files and providers.
+// Later runs with the same image, options and files start from that image.
+// Changes made after startup, e.g. via AdminClient, are not part of the image.
+// Stale images can be removed with docker image prune --filter label=org.testcontainers.keycloak.warm-start.
+func WithWarmStart() testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		req.Env[keycloakWarmStartEnv] = "true"
+
+		return nil
+	}
+}
+
+// prepareWarmStart returns the tag of the warm start image for the request and whether it already exists.
+// If it exists, the request is changed to start from it.
+func prepareWarmStart(ctx context.Context, req *testcontainers.GenericContainerRequest) (string, bool, error) {
+	fingerprint, err := requestFingerprint(req)
+	if err != nil {
+		return "", false, err
+	}
+	tag := warmStartRepository + ":" + fingerprint[:32]
+
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	defer cli.Close()
+
+	images, err := cli.ImageList(ctx, dockerclient.ImageListOptions{
+		Filters: make(dockerclient.Filters).Add("reference", tag),
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if len(images.Items) == 0 {
+		return tag, false, nil
+	}
+
+	// The realms already exist in the dev-file store of the image, so they are not imported again.
+	req.Image = tag
+	req.ImageSubstitutors = nil
+	req.Files = slices.DeleteFunc(slices.Clone(req.Files), isRealmImportFile(req))
+	req.Cmd = slices.DeleteFunc(slices.Clone(req.Cmd), func(arg string) bool {
+		return arg == importRealmArg
+	})
+	delete(req.Env, legacyImportEnv)
+
+	return tag, true, nil
+}
+
+// isRealmImportFile returns whether a file of the request is a realm to import,
+// from the import directory or, for legacy images, listed in KEYCLOAK_IMPORT.
+func isRealmImportFile(req *testcontainers.GenericContainerRequest) func(f testcontainers.ContainerFile) bool {
+	var legacyImports []string
+	if imports := req.Env[legacyImportEnv]; imports != "" {
+		legacyImports = strings.Split(imports, ",")
+	}
+	return func(f testcontainers.ContainerFile) bool {
+		return strings.HasPrefix(f.ContainerFilePath, defaultRealmImport) || slices.Contains(legacyImports, f.ContainerFilePath)
+	}
+}
+
+// commitWarmStart commits the started container into the warm start image with the given tag.
+// The container is stopped for the commit, so Keycloak shuts down its dev-file store cleanly,
+// and started again afterwards.
+func commitWarmStart(ctx context.Context, container testcontainers.Container, tag string) error {
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	if err = container.Stop(ctx, nil); err != nil {
+		return fmt.Errorf("stop container for warm start image %s: %w", tag, err)
+	}
+
+	_, err = cli.ContainerCommit(ctx, container.GetContainerID(), dockerclient.ContainerCommitOptions{
+		Reference: tag,
+		Comment:   "testcontainers-keycloak warm start",
+		Changes:   []string{fmt.Sprintf("LABEL %s=%s", warmStartFingerprintLabel, tag)},
+	})
+	if err != nil {
+		return fmt.Errorf("commit warm start image %s: %w", tag, err)
+	}
+
+	if err = container.Start(ctx); err != nil {
+		return fmt.Errorf("restart container after commit of warm start image %s: %w", tag, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package keycloak
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestIsRealmImportFile(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Env: map[string]string{legacyImportEnv: legacyRealmImport + "realm.json"},
+			Files: []testcontainers.ContainerFile{
+				{ContainerFilePath: defaultRealmImport + "realm.json"},
+				{ContainerFilePath: legacyRealmImport + "realm.json"},
+				{ContainerFilePath: legacyRealmImport + "other.txt"},
+				{ContainerFilePath: tlsFilePath + "/tls.crt"},
+				{ContainerFilePath: defaultProviders + "provider.jar"},
+				{ContainerFilePath: keycloakConfigFile},
+			},
+		},
+	}
+
+	var kept []string
+	for _, f := range slices.DeleteFunc(slices.Clone(req.Files), isRealmImportFile(req)) {
+		kept = append(kept, f.ContainerFilePath)
+	}
+	want := []string{legacyRealmImport + "other.txt", tlsFilePath + "/tls.crt", defaultProviders + "provider.jar", keycloakConfigFile}
+	if !slices.Equal(kept, want) {
+		t.Errorf("files without realm imports = %v, want %v", kept, want)
+	}
+}
+
+func TestKeycloakContainer_WithWarmStart(t *testing.T) {
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		container, err := Run(ctx,
+			"keycloak/keycloak:26.0",
+			WithRealmImportFile("testdata/realm-export.json"),
+			WithWarmStart(),
+		)
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+			return
+		}
+
+		testcontainers.CleanupContainer(t, container)
+
+		inspect, err := container.Inspect(ctx)
+		if err != nil {
+			t.Errorf("Inspect() error = %v", err)
+			return
+		}
+		if i > 0 && !strings.HasPrefix(inspect.Config.Image, warmStartRepository+":") {
+			t.Errorf("second run started from image %s, want warm start image", inspect.Config.Image)
+		}
+
+		adminClient, err := container.GetAdminClient(ctx)
+		if err != nil {
+			t.Errorf("GetAdminClient() error = %v", err)
+			return
+		}
+
+		c, err := adminClient.GetClient(realm, client)
+		if err != nil {
+			t.Errorf("GetClient() error = %v", err)
+			return
+		}
+		if *c.ClientID != client {
+			t.Errorf("GetClient() = %v, want %v", *c.ClientID, client)
+		}
+	}
+}